@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/analyzer"
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+	"github.com/danial2026/git_guardian_mcp/pkg/tests"
+)
+
+// pushRef is one line git writes to a pre-push hook's stdin
+type pushRef struct {
+	localRef  string
+	localSHA  string
+	remoteRef string
+	remoteSHA string
+}
+
+// runHook implements "git-guardian-mcp hook <name> ..." and returns the exit code
+func runHook(args []string) int {
+	if len(args) == 0 || args[0] != "pre-push" {
+		fmt.Fprintln(os.Stderr, "usage: git-guardian-mcp hook pre-push [-config path] [-new-issues-only] [-run-all] <remote> [<url>]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("hook pre-push", flag.ContinueOnError)
+	configPath := flags.String("config", "", "guardian config file (default <repo>/.mcp.yml)")
+	newIssuesOnly := flags.Bool("new-issues-only", false, "only fail on issues in changed lines")
+	runAll := flags.Bool("run-all", false, "run every pipeline stage even after one fails")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	remote := "origin"
+	if flags.NArg() > 0 {
+		remote = flags.Arg(0)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := runPrePush(ctx, os.Stdin, os.Stdout, remote, validation{configPath: *configPath, newIssuesOnly: *newIssuesOnly, runAll: *runAll}); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runPrePush validates exactly the commits each pushed ref sends to remote,
+// using the options in v; the repository and progress output are filled in here
+func runPrePush(ctx context.Context, stdin io.Reader, out io.Writer, remote string, v validation) error {
+	repoPath, err := repoRoot()
+	if err != nil {
+		return err
+	}
+	v.repoPath = repoPath
+	if v.configPath == "" {
+		v.configPath = filepath.Join(repoPath, ".mcp.yml")
+	}
+
+	refs, err := parsePushRefs(stdin)
+	if err != nil {
+		return err
+	}
+
+	v.cfg, err = config.Load(v.configPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	gitAnalyzer := newGitAnalyzer(repoPath, v.cfg)
+	seen := make(map[string]bool)
+	failed := false
+	for _, ref := range refs {
+		if git.IsZeroSHA(ref.localSHA) {
+			continue // deleting a branch, nothing to validate
+		}
+		fmt.Fprintf(out, "Validating push of %s to %s/%s...\n", ref.localRef, remote, strings.TrimPrefix(ref.remoteRef, "refs/heads/"))
+
+		commits, err := newPushCommits(gitAnalyzer, remote, ref, seen)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			continue
+		}
+		ok, err := validateRef(ctx, out, v, gitAnalyzer, ref.localSHA, commits)
+		if err != nil {
+			return err
+		}
+		failed = failed || !ok
+	}
+
+	if len(seen) == 0 {
+		fmt.Fprintln(out, "✓ No new commits to validate - push allowed")
+	}
+	if failed {
+		return fmt.Errorf("push validation failed (skip with git push --no-verify)")
+	}
+	return nil
+}
+
+// newPushCommits returns the commits ref sends to remote that an earlier ref
+// of the same push didn't already send, marking them in seen
+func newPushCommits(gitAnalyzer *git.Analyzer, remote string, ref pushRef, seen map[string]bool) ([]git.Commit, error) {
+	pushed, err := gitAnalyzer.GetPushCommits(remote, ref.localSHA, ref.remoteSHA)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]git.Commit, 0, len(pushed))
+	for _, commit := range pushed {
+		if !seen[commit.Hash] {
+			seen[commit.Hash] = true
+			commits = append(commits, commit)
+		}
+	}
+	return commits, nil
+}
+
+// validateRef validates commits against the tree at sha: in place when the
+// working tree is a clean checkout of it, otherwise in a temporary worktree,
+// and reports whether the push may go ahead
+func validateRef(ctx context.Context, out io.Writer, v validation, gitAnalyzer *git.Analyzer, sha string, commits []git.Commit) (bool, error) {
+	if !gitAnalyzer.IsCheckedOut(sha) {
+		dir, remove, err := gitAnalyzer.AddWorktree(sha)
+		if err != nil {
+			return false, err
+		}
+		defer remove()
+		fmt.Fprintf(out, "  checked out %.8s in %s\n", sha, dir)
+		v.repoPath = dir
+		gitAnalyzer = newGitAnalyzer(dir, v.cfg)
+	}
+
+	v.onCheck = func(index, total int, results []analyzer.CheckResult) {
+		for _, result := range results {
+			fmt.Fprintf(out, "  [%d/%d] check %s %s\n", index, total, result.Tool, outcome(result.Success))
+		}
+	}
+	v.onTest = func(index, total int, result tests.TestResult) {
+		fmt.Fprintf(out, "  [%d/%d] test %s %s in %.1fs\n", index, total, result.Name, result.Status, result.Duration)
+	}
+	report, err := validateCommits(ctx, v, gitAnalyzer, commits)
+	if err != nil {
+		return false, fmt.Errorf("failed to run validation: %w", err)
+	}
+
+	printPushReport(out, report)
+	return report.Success, nil
+}
+
+// parsePushRefs reads "<local ref> <local sha> <remote ref> <remote sha>" lines
+func parsePushRefs(r io.Reader) ([]pushRef, error) {
+	refs := make([]pushRef, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected pre-push input %q", scanner.Text())
+		}
+		refs = append(refs, pushRef{localRef: fields[0], localSHA: fields[1], remoteRef: fields[2], remoteSHA: fields[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pre-push input: %w", err)
+	}
+	return refs, nil
+}
+
+// printPushReport prints a summary followed by every failure that blocks the push
+func printPushReport(out io.Writer, report *validatePushResult) {
+	fmt.Fprintf(out, "\nCommits: %d, changed files: %d\n", report.Commits, report.ChangedFiles)
+
+	for _, check := range report.Checks {
+		if check.Success {
+			continue
+		}
+		label := "blocking"
+		if !check.Blocking {
+			label = "advisory"
+		}
+		fmt.Fprintf(out, "\n%s (%s): %s\n", check.Tool, label, check.Message)
+		for _, e := range check.Errors {
+			fmt.Fprintf(out, "  %s\n", e)
+		}
+	}
+
+	for _, test := range report.Tests {
+		if test.Success {
+			continue
+		}
+		if test.Status == tests.StatusSkipped {
+			fmt.Fprintf(out, "\ntest %s %s\n", test.Name, test.Error)
+			continue
+		}
+		fmt.Fprintf(out, "\ntest %s failed: %s\n", test.Name, test.Error)
+		if test.Output != "" {
+			fmt.Fprintf(out, "%s\n", strings.TrimRight(test.Output, "\n"))
+		}
+	}
+
+	if report.Message != "" {
+		fmt.Fprintf(out, "\n%s\n", report.Message)
+	}
+	if report.Success {
+		fmt.Fprintln(out, "\n✓ All pre-push checks passed - push allowed")
+	} else {
+		fmt.Fprintln(out, "\n❌ Push validation failed!")
+	}
+}
+
+func repoRoot() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not inside a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/danial2026/git_guardian_mcp/pkg/analyzer"
 	"github.com/danial2026/git_guardian_mcp/pkg/config"
 	"github.com/danial2026/git_guardian_mcp/pkg/git"
 	"github.com/danial2026/git_guardian_mcp/pkg/mcp"
+	"github.com/danial2026/git_guardian_mcp/pkg/policy"
+	"github.com/danial2026/git_guardian_mcp/pkg/secrets"
 	"github.com/danial2026/git_guardian_mcp/pkg/tests"
 )
 
@@ -18,6 +26,15 @@ func main() {
 		fmt.Println("git-guardian-mcp v1.0.0")
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "hook" {
+		os.Exit(runHook(os.Args[2:]))
+	}
+
+	transport := flag.String("transport", "stdio", "transport to serve: stdio or http")
+	addr := flag.String("addr", "127.0.0.1:7391", "bind address for the http transport")
+	token := flag.String("token", os.Getenv("GIT_GUARDIAN_TOKEN"), "bearer token required by the http transport (default $GIT_GUARDIAN_TOKEN)")
+	allowHosts := flag.String("allow-host", "", "comma-separated host names accepted in Host and Origin headers besides loopback")
+	flag.Parse()
 
 	// Log to file to keep Cursor's output clean
 	logFile := "/tmp/git-guardian-mcp.log"
@@ -31,213 +48,646 @@ func main() {
 	server := mcp.NewServer(logger)
 
 	// Register MCP tools
-	server.RegisterTool("analyze_commits", "Analyze unpushed commits for issues", handleAnalyzeCommits)
-	server.RegisterTool("run_checks", "Run syntax and static analysis checks", handleRunChecks)
-	server.RegisterTool("run_tests", "Execute configured test suites", handleRunTests)
-	server.RegisterTool("explain_failure", "Get detailed explanation of a failure", handleExplainFailure)
-	server.RegisterTool("validate_push", "Complete validation before push", handleValidatePush)
+	server.RegisterTool("analyze_commits", "Analyze unpushed commits for issues", analyzeCommitsInput{}, analyzeCommitsResult{}, handleAnalyzeCommits).
+		Annotations = &mcp.ToolAnnotations{Title: "Analyze unpushed commits", ReadOnlyHint: true, IdempotentHint: true}
+	server.RegisterTool("run_checks", "Run syntax and static analysis checks", runChecksInput{}, runChecksResult{}, handleRunChecks).
+		Annotations = &mcp.ToolAnnotations{Title: "Run static checks", ReadOnlyHint: true, IdempotentHint: true}
+	server.RegisterTool("run_tests", "Execute configured test suites", runTestsInput{}, runTestsResult{}, handleRunTests).
+		Annotations = &mcp.ToolAnnotations{Title: "Run test suites", IdempotentHint: true}
+	server.RegisterTool("explain_failure", "Get detailed explanation of a failure", explainFailureInput{}, explainFailureResult{}, handleExplainFailure).
+		Annotations = &mcp.ToolAnnotations{Title: "Explain a failure", ReadOnlyHint: true, IdempotentHint: true}
+	server.RegisterTool("validate_push", "Complete validation before push", validatePushInput{}, validatePushResult{}, handleValidatePush).
+		Annotations = &mcp.ToolAnnotations{Title: "Validate push", IdempotentHint: true}
+
+	// Register MCP resources and prompts
+	registerResources(server)
+	registerPrompts(server)
 
 	// Start MCP server
-	if err := server.Start(); err != nil {
+	switch *transport {
+	case "stdio":
+		err = server.Start()
+	case "http":
+		err = server.ListenAndServe(mcp.HTTPConfig{Addr: *addr, Token: *token, AllowedHosts: splitList(*allowHosts)})
+	default:
+		fmt.Fprintf(os.Stderr, "unknown transport %q (want stdio or http)\n", *transport)
+		os.Exit(2)
+	}
+	if err != nil {
 		logger.Fatalf("Server error: %v", err)
 	}
 }
 
-func handleAnalyzeCommits(params json.RawMessage) (interface{}, error) {
-	var input struct {
-		RepoPath string `json:"repo_path"`
-		Remote   string `json:"remote"`
-		Branch   string `json:"branch"`
-	}
+type analyzeCommitsInput struct {
+	RepoPath string `json:"repo_path" description:"Path to the git repository" default:"."`
+	Remote   string `json:"remote" description:"Remote to compare against" default:"origin"`
+	Branch   string `json:"branch" description:"Branch to analyze (defaults to the current branch)"`
+
+	ConfigPath string `json:"config_path" description:"Path to the guardian config file declaring the commit policy" default:".mcp.yml"`
+}
+
+type analyzeCommitsResult struct {
+	Success          bool               `json:"success"`
+	Commits          []git.Commit       `json:"commits"`
+	TotalCommits     int                `json:"total_commits"`
+	ChangedFiles     []string           `json:"changed_files"`
+	PolicyViolations []policy.Violation `json:"policy_violations"`
+}
+
+type runChecksInput struct {
+	RepoPath string   `json:"repo_path" description:"Path to the git repository" default:"."`
+	Files    []string `json:"files" description:"Files to check, as absolute paths" required:"true"`
+
+	ConfigPath string `json:"config_path" description:"Path to the guardian config file declaring custom checks" default:".mcp.yml"`
+}
+
+type runChecksResult struct {
+	Success bool                   `json:"success"`
+	Results []analyzer.CheckResult `json:"results"`
+}
+
+type runTestsInput struct {
+	RepoPath   string `json:"repo_path" description:"Path to the git repository" default:"."`
+	ConfigPath string `json:"config_path" description:"Path to the guardian config file" default:".mcp.yml"`
+}
+
+type runTestsResult struct {
+	Success bool               `json:"success"`
+	Results []tests.TestResult `json:"results"`
+}
+
+type explainFailureInput struct {
+	FailureType string `json:"failure_type" description:"Name of the failed check, e.g. gofmt, go vet or test" required:"true"`
+	Details     string `json:"details" description:"Raw output of the failure to include in the explanation"`
+}
+
+type explainFailureResult struct {
+	Success     bool   `json:"success"`
+	Explanation string `json:"explanation"`
+}
 
+// validatePushResult is the report produced by validate_push
+type validatePushResult struct {
+	Success      bool                   `json:"success"`
+	Message      string                 `json:"message,omitempty"`
+	Commits      int                    `json:"commits"`
+	ChangedFiles int                    `json:"changed_files"`
+	Checks       []analyzer.CheckResult `json:"checks"`
+	Tests        []tests.TestResult     `json:"tests"`
+	Stages       []stageResult          `json:"stages,omitempty"`
+}
+
+type validatePushInput struct {
+	RepoPath   string `json:"repo_path" description:"Path to the git repository" default:"."`
+	Remote     string `json:"remote" description:"Remote the push targets" default:"origin"`
+	Branch     string `json:"branch" description:"Branch being pushed (defaults to the current branch)"`
+	ConfigPath string `json:"config_path" description:"Path to the guardian config file" default:".mcp.yml"`
+
+	NewIssuesOnly bool `json:"new_issues_only" description:"Only fail on issues in lines changed by the unpushed commits; report pre-existing ones as informational"`
+	RunAll        bool `json:"run_all" description:"Run every pipeline stage even after one fails, e.g. in CI"`
+}
+
+func handleAnalyzeCommits(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var input analyzeCommitsInput
 	if err := json.Unmarshal(params, &input); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	if input.RepoPath == "" {
-		input.RepoPath = "."
-	}
-	if input.Remote == "" {
-		input.Remote = "origin"
+	cfg, err := config.Load(input.ConfigPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	gitAnalyzer := git.NewAnalyzer(input.RepoPath)
+	gitAnalyzer := newGitAnalyzer(input.RepoPath, cfg)
 	commits, err := gitAnalyzer.GetUnpushedCommits(input.Remote, input.Branch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
 	}
+	if reports.touch(input.RepoPath, "") {
+		reports.server.NotifyResourceListChanged()
+	}
+	commitPolicy, err := newCommitPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	return map[string]interface{}{
-		"success":       true,
-		"commits":       commits,
-		"total_commits": len(commits),
-		"changed_files": gitAnalyzer.GetChangedFiles(commits),
+	return &analyzeCommitsResult{
+		Success:          true,
+		Commits:          commits,
+		TotalCommits:     len(commits),
+		ChangedFiles:     gitAnalyzer.GetChangedFiles(commits),
+		PolicyViolations: commitPolicy.Check(commits),
 	}, nil
 }
 
-func handleRunChecks(params json.RawMessage) (interface{}, error) {
-	var input struct {
-		RepoPath string   `json:"repo_path"`
-		Files    []string `json:"files"`
-	}
-
+func handleRunChecks(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var input runChecksInput
 	if err := json.Unmarshal(params, &input); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	if input.RepoPath == "" {
-		input.RepoPath = "."
+	cfg, err := config.Load(input.ConfigPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	analyzer := analyzer.NewAnalyzer(input.RepoPath)
-	results := analyzer.RunChecks(input.Files)
+	analyzer, err := newAnalyzer(input.RepoPath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	analyzer.SetProgress(checkProgress(mcp.ProgressFromContext(ctx)))
+	results := analyzer.RunChecks(ctx, input.Files)
 
-	hasErrors := false
-	for _, result := range results {
-		if !result.Success {
-			hasErrors = true
-			break
-		}
+	return &runChecksResult{
+		Success: !hasBlockingCheckFailures(results),
+		Results: results,
+	}, nil
+}
+
+func handleRunTests(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var input runTestsInput
+	if err := json.Unmarshal(params, &input); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	return map[string]interface{}{
-		"success": !hasErrors,
-		"results": results,
+	cfg, err := config.Load(input.ConfigPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg, err = askForTestCommand(ctx, input.ConfigPath, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	runner := tests.NewRunner(input.RepoPath, cfg)
+	runner.SetProgress(testProgress(mcp.ProgressFromContext(ctx)))
+	results := runner.RunAll(ctx)
+
+	return &runTestsResult{
+		Success: !hasBlockingTestFailures(results),
+		Results: results,
 	}, nil
 }
 
-func handleRunTests(params json.RawMessage) (interface{}, error) {
-	var input struct {
-		RepoPath   string `json:"repo_path"`
-		ConfigPath string `json:"config_path"`
+func handleExplainFailure(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var input explainFailureInput
+	if err := json.Unmarshal(params, &input); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
+	explanation := analyzer.ExplainFailure(input.FailureType, input.Details)
+
+	return &explainFailureResult{
+		Success:     true,
+		Explanation: explanation,
+	}, nil
+}
+
+func handleValidatePush(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var input validatePushInput
 	if err := json.Unmarshal(params, &input); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	if input.RepoPath == "" {
-		input.RepoPath = "."
+	cfg, err := config.Load(input.ConfigPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
-	if input.ConfigPath == "" {
-		input.ConfigPath = ".mcp.yml"
+	gitAnalyzer := newGitAnalyzer(input.RepoPath, cfg)
+	commits, err := gitAnalyzer.GetUnpushedCommits(input.Remote, input.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
 	}
 
-	cfg, err := config.Load(input.ConfigPath)
+	if len(commits) == 0 {
+		report := &validatePushResult{
+			Success: true,
+			Message: "No unpushed commits to validate",
+			Checks:  []analyzer.CheckResult{},
+			Tests:   []tests.TestResult{},
+		}
+		reports.save(input.RepoPath, input.ConfigPath, report)
+		return report, nil
+	}
+
+	progress := mcp.ProgressFromContext(ctx)
+	report, err := validateCommits(ctx, validation{
+		repoPath:      input.RepoPath,
+		configPath:    input.ConfigPath,
+		cfg:           cfg,
+		newIssuesOnly: input.NewIssuesOnly,
+		runAll:        input.RunAll,
+		onCheck:       checkProgress(progress),
+		onTest:        testProgress(progress),
+	}, gitAnalyzer, commits)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, err
 	}
+	if ctx.Err() == nil {
+		reports.save(input.RepoPath, input.ConfigPath, report)
+	}
+	return report, nil
+}
 
-	runner := tests.NewRunner(input.RepoPath, cfg)
-	results := runner.RunAll()
+// validation holds the options shared by validate_push and the pre-push hook
+type validation struct {
+	repoPath      string
+	configPath    string
+	cfg           *config.Config // loaded from configPath, nil when it doesn't exist
+	newIssuesOnly bool
+	runAll        bool
+	// onCheck and onTest number checks and tests as steps of the whole validation
+	onCheck analyzer.ProgressFunc
+	onTest  tests.ProgressFunc
+}
 
-	hasBlockingFailures := false
-	for _, result := range results {
-		if !result.Success && result.Blocking {
-			hasBlockingFailures = true
-			break
+// stageResult summarises one pipeline stage of a validation
+type stageResult struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`   // passed, failed or skipped
+	Duration float64 `json:"duration"` // in seconds
+}
+
+// validateCommits runs static analysis, secret scanning, the commit policy
+// and the configured tests against the files touched by commits, stage by
+// stage, skipping the stages after one with a blocking failure unless runAll
+func validateCommits(ctx context.Context, v validation, gitAnalyzer *git.Analyzer, commits []git.Commit) (*validatePushResult, error) {
+	pipeline := v.cfg.PipelineOrDefault()
+	runAll := v.runAll || pipeline.RunAll
+	run, err := newPipelineRun(v, v.cfg, gitAnalyzer, commits)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &validatePushResult{
+		Success:      true,
+		Commits:      len(commits),
+		ChangedFiles: len(run.changedFiles),
+		Checks:       make([]analyzer.CheckResult, 0),
+		Tests:        make([]tests.TestResult, 0),
+		Stages:       make([]stageResult, 0),
+	}
+	stoppedAt := ""
+	for i, name := range pipeline.StageOrder() {
+		if stoppedAt != "" || ctx.Err() != nil {
+			report.Stages = append(report.Stages, stageResult{Name: name, Status: tests.StatusSkipped})
+			continue
+		}
+		start := time.Now()
+		stageChecks, stageTests, err := run.stage(ctx, name, i == 0)
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, stageChecks...)
+		report.Tests = append(report.Tests, stageTests...)
+
+		status := tests.StatusPassed
+		if hasBlockingCheckFailures(stageChecks) || hasBlockingTestFailures(stageTests) {
+			status, report.Success = tests.StatusFailed, false
+			if !runAll {
+				stoppedAt = name
+			}
 		}
+		report.Stages = append(report.Stages, stageResult{Name: name, Status: status, Duration: time.Since(start).Seconds()})
 	}
+	if stoppedAt != "" {
+		report.Message = fmt.Sprintf("Stopped after the %s stage failed; later stages were skipped (set run_all to run them)", stoppedAt)
+	}
+	return report, nil
+}
 
-	return map[string]interface{}{
-		"success": !hasBlockingFailures,
-		"results": results,
-	}, nil
+// pipelineRun holds the analyzer, runner and progress count of one validation
+type pipelineRun struct {
+	v              validation
+	cfg            *config.Config
+	gitAnalyzer    *git.Analyzer
+	commits        []git.Commit
+	changedFiles   []string
+	staticAnalyzer *analyzer.Analyzer
+	runner         *tests.Runner // nil without a config file
+	steps, total   int
 }
 
-func handleExplainFailure(params json.RawMessage) (interface{}, error) {
-	var input struct {
-		FailureType string `json:"failure_type"`
-		Details     string `json:"details"`
+func newPipelineRun(v validation, cfg *config.Config, gitAnalyzer *git.Analyzer, commits []git.Commit) (*pipelineRun, error) {
+	staticAnalyzer, err := newAnalyzer(v.repoPath, cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := json.Unmarshal(params, &input); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+	run := &pipelineRun{
+		v:              v,
+		cfg:            cfg,
+		gitAnalyzer:    gitAnalyzer,
+		commits:        commits,
+		changedFiles:   gitAnalyzer.GetChangedFiles(commits),
+		staticAnalyzer: staticAnalyzer,
+	}
+	if cfg != nil {
+		run.runner = tests.NewRunner(v.repoPath, cfg)
 	}
+	if v.newIssuesOnly {
+		changedLines, err := gitAnalyzer.GetChangedLines(commits)
+		if err != nil {
+			return nil, err
+		}
+		staticAnalyzer.SetLineFilter(changedLines.Contains)
+	}
+	run.countSteps(cfg.PipelineOrDefault().StageOrder())
+	return run, nil
+}
 
-	explanation := analyzer.ExplainFailure(input.FailureType, input.Details)
+// countSteps numbers checks and tests as steps of one count, so progress
+// keeps increasing across stages; the commit checks count as one step
+func (run *pipelineRun) countSteps(stages []string) {
+	run.total = 1
+	for _, name := range stages {
+		run.total += run.staticAnalyzer.Count(run.changedFiles, name)
+		if run.runner != nil {
+			run.total += run.runner.Count(name)
+		}
+	}
+	run.staticAnalyzer.SetProgress(func(_, _ int, results []analyzer.CheckResult) {
+		run.checkStep(results)
+	})
+	if run.runner != nil {
+		run.runner.SetProgress(func(_, _ int, result tests.TestResult) {
+			run.steps++
+			if run.v.onTest != nil {
+				run.v.onTest(run.steps, run.total, result)
+			}
+		})
+	}
+}
 
-	return map[string]interface{}{
-		"success":     true,
-		"explanation": explanation,
-	}, nil
+func (run *pipelineRun) checkStep(results []analyzer.CheckResult) {
+	run.steps++
+	if run.v.onCheck != nil {
+		run.v.onCheck(run.steps, run.total, results)
+	}
 }
 
-func handleValidatePush(params json.RawMessage) (interface{}, error) {
-	var input struct {
-		RepoPath   string `json:"repo_path"`
-		Remote     string `json:"remote"`
-		Branch     string `json:"branch"`
-		ConfigPath string `json:"config_path"`
+// stage runs the checks and tests of one pipeline stage; secrets and commit
+// messages are cheap and checked with the first
+func (run *pipelineRun) stage(ctx context.Context, name string, first bool) ([]analyzer.CheckResult, []tests.TestResult, error) {
+	checks := run.staticAnalyzer.RunStage(ctx, run.changedFiles, name)
+	if first {
+		commitChecks, err := checkCommits(run.cfg, run.gitAnalyzer, run.v.repoPath, run.commits)
+		if err != nil {
+			return nil, nil, err
+		}
+		checks = append(checks, commitChecks...)
+		run.checkStep(commitChecks)
 	}
 
-	if err := json.Unmarshal(params, &input); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+	stageTests := []tests.TestResult{}
+	if run.runner != nil {
+		stageTests = run.runner.RunStage(ctx, name)
 	}
+	return checks, stageTests, nil
+}
 
-	// Set defaults
-	if input.RepoPath == "" {
-		input.RepoPath = "."
+// checkCommits scans the commits for secrets and checks their messages
+// against the commit policy
+func checkCommits(cfg *config.Config, gitAnalyzer *git.Analyzer, repoPath string, commits []git.Commit) ([]analyzer.CheckResult, error) {
+	secretsResult, err := scanSecrets(gitAnalyzer, repoPath, commits)
+	if err != nil {
+		return nil, err
 	}
-	if input.Remote == "" {
-		input.Remote = "origin"
+	commitPolicy, err := newCommitPolicy(cfg)
+	if err != nil {
+		return nil, err
 	}
-	if input.ConfigPath == "" {
-		input.ConfigPath = ".mcp.yml"
+	return []analyzer.CheckResult{secretsResult, checkCommitPolicy(commitPolicy, commits)}, nil
+}
+
+// newGitAnalyzer creates a git analyzer using the range fallback configured
+// in cfg, which may be nil when there is no config file
+func newGitAnalyzer(repoPath string, cfg *config.Config) *git.Analyzer {
+	a := git.NewAnalyzer(repoPath)
+	if cfg != nil && cfg.Range != nil {
+		a.SetFallback(cfg.Range.Fallback, cfg.Range.DefaultBranch)
 	}
+	return a
+}
 
-	// Get unpushed commits
-	gitAnalyzer := git.NewAnalyzer(input.RepoPath)
-	commits, err := gitAnalyzer.GetUnpushedCommits(input.Remote, input.Branch)
+// newAnalyzer creates an analyzer with the custom checks declared in cfg,
+// which may be nil when there is no config file
+func newAnalyzer(repoPath string, cfg *config.Config) (*analyzer.Analyzer, error) {
+	a := analyzer.NewAnalyzer(repoPath)
+	if cfg == nil {
+		return a, nil
+	}
+
+	for _, check := range cfg.Checks {
+		checker, err := analyzer.NewCommandChecker(repoPath, check)
+		if err != nil {
+			return nil, fmt.Errorf("invalid check in config: %w", err)
+		}
+		a.Register(checker)
+	}
+	return a, nil
+}
+
+// scanSecrets scans the lines added by the commits for credentials
+func scanSecrets(gitAnalyzer *git.Analyzer, repoPath string, commits []git.Commit) (analyzer.CheckResult, error) {
+	scanner, err := secrets.NewScanner(repoPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
+		return analyzer.CheckResult{}, err
 	}
 
-	if len(commits) == 0 {
-		return map[string]interface{}{
-			"success": true,
-			"message": "No unpushed commits to validate",
+	if err := gitAnalyzer.LoadDiffs(commits); err != nil {
+		return analyzer.CheckResult{}, err
+	}
+	diags := make([]analyzer.Diagnostic, 0)
+	for _, commit := range commits {
+		for _, finding := range scanner.ScanDiff(commit.Hash, commit.Diff) {
+			diags = append(diags, findingDiagnostic(finding))
+		}
+	}
+
+	if !analyzer.HasErrors(diags) {
+		message := "No secrets found in unpushed commits"
+		if len(diags) > 0 {
+			message = fmt.Sprintf("No secrets found in unpushed commits (%d warnings)", len(diags))
+		}
+		return analyzer.CheckResult{
+			Tool:        "secrets",
+			Severity:    "info",
+			Message:     message,
+			Success:     true,
+			Blocking:    true,
+			Diagnostics: diags,
 		}, nil
 	}
 
-	changedFiles := gitAnalyzer.GetChangedFiles(commits)
+	errs := make([]string, 0, len(diags))
+	for _, d := range diags {
+		errs = append(errs, d.String())
+	}
+	return analyzer.CheckResult{
+		Tool:        "secrets",
+		File:        diags[0].File,
+		Line:        diags[0].Line,
+		Severity:    "error",
+		Message:     fmt.Sprintf("%d possible secrets found in unpushed commits", len(diags)),
+		Success:     false,
+		Errors:      errs,
+		Blocking:    true,
+		Diagnostics: diags,
+	}, nil
+}
+
+// findingDiagnostic reports a secret with the commit that introduced it
+func findingDiagnostic(finding secrets.Finding) analyzer.Diagnostic {
+	message := finding.Description
+	if finding.Secret != "" {
+		message = fmt.Sprintf("%s %s", finding.Description, finding.Secret)
+	}
+	return analyzer.Diagnostic{
+		File:     finding.File,
+		Line:     finding.Line,
+		Rule:     finding.Rule,
+		Message:  fmt.Sprintf("%s introduced in %.8s", message, finding.Commit),
+		Severity: finding.Severity,
+		Commit:   finding.Commit,
+	}
+}
 
-	// Run static analysis
-	analyzer := analyzer.NewAnalyzer(input.RepoPath)
-	checkResults := analyzer.RunChecks(changedFiles)
+// newCommitPolicy builds the commit policy from cfg, which may be nil, using
+// the advisory default when none is configured
+func newCommitPolicy(cfg *config.Config) (*policy.Policy, error) {
+	return policy.New(*commitPolicyConfig(cfg))
+}
 
-	hasCheckErrors := false
-	for _, result := range checkResults {
-		if !result.Success {
-			hasCheckErrors = true
-			break
+// commitPolicyConfig returns the commit policy in effect for cfg, which may be nil
+func commitPolicyConfig(cfg *config.Config) *config.CommitPolicy {
+	if cfg != nil && cfg.CommitPolicy != nil {
+		return cfg.CommitPolicy
+	}
+	return config.DefaultCommitPolicy()
+}
+
+// checkCommitPolicy reports commit message violations as a check result
+func checkCommitPolicy(commitPolicy *policy.Policy, commits []git.Commit) analyzer.CheckResult {
+	violations := commitPolicy.Check(commits)
+	if len(violations) == 0 {
+		return analyzer.CheckResult{
+			Tool:     "commit-policy",
+			Severity: "info",
+			Message:  "All commit messages follow the commit policy",
+			Success:  true,
+			Blocking: commitPolicy.Blocking(),
 		}
 	}
 
-	// Run tests
-	cfg, err := config.Load(input.ConfigPath)
-	var testResults []tests.TestResult
-	hasBlockingTestFailures := false
+	diags := make([]analyzer.Diagnostic, 0, len(violations))
+	errs := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		d := analyzer.Diagnostic{
+			Rule:     violation.Rule,
+			Message:  fmt.Sprintf("%q: %s", violation.Subject, violation.Message),
+			Severity: "error",
+			Commit:   violation.Commit,
+		}
+		if !commitPolicy.Blocking() {
+			d.Severity = "warning"
+		}
+		diags = append(diags, d)
+		errs = append(errs, d.String())
+	}
+	return analyzer.CheckResult{
+		Tool:        "commit-policy",
+		Severity:    diags[0].Severity,
+		Message:     fmt.Sprintf("%d commit message policy violations", len(violations)),
+		Success:     false,
+		Errors:      errs,
+		Blocking:    commitPolicy.Blocking(),
+		Diagnostics: diags,
+	}
+}
 
-	if err == nil {
-		runner := tests.NewRunner(input.RepoPath, cfg)
-		testResults = runner.RunAll()
+func hasBlockingCheckFailures(results []analyzer.CheckResult) bool {
+	for _, result := range results {
+		if !result.Success && result.Blocking {
+			return true
+		}
+	}
+	return false
+}
 
-		for _, result := range testResults {
-			if !result.Success && result.Blocking {
-				hasBlockingTestFailures = true
-				break
-			}
+func hasBlockingTestFailures(results []tests.TestResult) bool {
+	for _, result := range results {
+		if !result.Success && result.Blocking {
+			return true
 		}
 	}
+	return false
+}
 
-	success := !hasCheckErrors && !hasBlockingTestFailures
+// askForTestCommand asks the local user for a one-off test command when no
+// config file exists, returning loadErr when the client can't or won't answer.
+// The answer runs as a plain argv command, never through a shell, and is
+// never requested from network clients
+func askForTestCommand(ctx context.Context, configPath string, loadErr error) (*config.Config, error) {
+	if !mcp.LocalClient(ctx) {
+		return nil, loadErr
+	}
+	answer, err := mcp.Elicit(ctx, fmt.Sprintf("No guardian config found at %s. Which test command should run?", configPath), &mcp.Schema{
+		Type: "object",
+		Properties: map[string]*mcp.Schema{
+			"command": {Type: "string", Description: "Test command to run from the repository root without shell syntax, e.g. go test ./..."},
+		},
+		Required: []string{"command"},
+	})
+	if err != nil || answer.Action != "accept" {
+		return nil, loadErr
+	}
 
-	return map[string]interface{}{
-		"success":       success,
-		"commits":       len(commits),
-		"changed_files": len(changedFiles),
-		"checks":        checkResults,
-		"tests":         testResults,
-	}, nil
+	command, _ := answer.Content["command"].(string)
+	if strings.TrimSpace(command) == "" {
+		return nil, loadErr
+	}
+	test := config.TestConfig{Name: "ad-hoc", Command: command, Mode: config.ModeArgv, Blocking: true, Timeout: 300}
+	if err := test.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid test command %q: %w", command, err)
+	}
+	return &config.Config{Tests: []config.TestConfig{test}}, nil
+}
+
+// checkProgress forwards completed checkers to the client as progress
+func checkProgress(progress *mcp.Progress) analyzer.ProgressFunc {
+	return func(index, total int, results []analyzer.CheckResult) {
+		outcomes := make([]string, 0, len(results))
+		for _, result := range results {
+			outcomes = append(outcomes, fmt.Sprintf("%s %s", result.Tool, outcome(result.Success)))
+		}
+		message := fmt.Sprintf("%d/%d: %s (%s elapsed)",
+			index, total, strings.Join(outcomes, ", "), progress.Elapsed().Round(time.Millisecond))
+		progress.Report(float64(index), float64(total), message)
+	}
+}
+
+// testProgress forwards completed tests to the client as progress
+func testProgress(progress *mcp.Progress) tests.ProgressFunc {
+	return func(index, total int, result tests.TestResult) {
+		message := fmt.Sprintf("%d/%d: test %s %s in %.1fs (%s elapsed)",
+			index, total, result.Name, result.Status, result.Duration, progress.Elapsed().Round(time.Millisecond))
+		progress.Report(float64(index), float64(total), message)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func outcome(success bool) string {
+	if success {
+		return "passed"
+	}
+	return "failed"
 }
@@ -1,11 +1,11 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 )
 
 // CheckResult represents the result of a static analysis check
@@ -19,315 +19,172 @@ type CheckResult struct {
 	Success  bool     `json:"success"`
 	Output   string   `json:"output,omitempty"`
 	Errors   []string `json:"errors,omitempty"`
+	Blocking bool     `json:"blocking"`
+
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
 }
 
+// ProgressFunc is called after each checker completes with its results;
+// index is 1-based and total is the number of checkers in the run
+type ProgressFunc func(index, total int, results []CheckResult)
+
+// LineFilter reports whether a line of a file belongs to the change under
+// review; line 0 stands for the file as a whole
+type LineFilter func(file string, line int) bool
+
 // Analyzer handles static analysis checks
 type Analyzer struct {
 	repoPath string
+	registry *Registry
+	progress ProgressFunc
+	changed  LineFilter
+}
+
+// plannedCheck is a checker together with the files it will check
+type plannedCheck struct {
+	checker Checker
+	files   []string
 }
 
 // NewAnalyzer creates a new analyzer
 func NewAnalyzer(repoPath string) *Analyzer {
 	return &Analyzer{
 		repoPath: repoPath,
+		registry: DefaultRegistry(),
 	}
 }
 
-// RunChecks runs all applicable checks on the given files
-func (a *Analyzer) RunChecks(files []string) []CheckResult {
-	results := make([]CheckResult, 0)
-
-	// Group files by type
-	filesByType := a.groupFilesByType(files)
-
-	// Run Go checks
-	if goFiles := filesByType["go"]; len(goFiles) > 0 {
-		results = append(results, a.checkGo(goFiles)...)
-	}
-
-	// Run Dart/Flutter checks
-	if dartFiles := filesByType["dart"]; len(dartFiles) > 0 {
-		results = append(results, a.checkDart(dartFiles)...)
-	}
-
-	// Run Bash checks
-	if bashFiles := filesByType["bash"]; len(bashFiles) > 0 {
-		results = append(results, a.checkBash(bashFiles)...)
-	}
-
-	// Run JavaScript/TypeScript checks
-	if jsFiles := filesByType["js"]; len(jsFiles) > 0 {
-		results = append(results, a.checkJavaScript(jsFiles)...)
-	}
-
-	return results
+// Register adds a checker to this analyzer, replacing a built-in one of the same name
+func (a *Analyzer) Register(c Checker) {
+	a.registry.Register(c)
 }
 
-func (a *Analyzer) groupFilesByType(files []string) map[string][]string {
-	groups := make(map[string][]string)
-
-	for _, file := range files {
-		// Check if file exists
-		if _, err := os.Stat(file); os.IsNotExist(err) {
-			continue
-		}
-
-		ext := strings.ToLower(filepath.Ext(file))
-		switch ext {
-		case ".go":
-			groups["go"] = append(groups["go"], file)
-		case ".dart":
-			groups["dart"] = append(groups["dart"], file)
-		case ".sh", ".bash":
-			groups["bash"] = append(groups["bash"], file)
-		case ".js", ".jsx", ".ts", ".tsx":
-			groups["js"] = append(groups["js"], file)
-		}
-	}
-
-	return groups
+// SetProgress registers a callback invoked as each check completes
+func (a *Analyzer) SetProgress(fn ProgressFunc) {
+	a.progress = fn
 }
 
-func (a *Analyzer) checkGo(files []string) []CheckResult {
-	results := make([]CheckResult, 0)
-
-	// Check if go is available
-	if !commandExists("go") {
-		return results
-	}
-
-	// Run gofmt
-	fmtResult := a.runGoFmt(files)
-	results = append(results, fmtResult)
-
-	// Run go vet
-	vetResult := a.runGoVet()
-	results = append(results, vetResult)
-
-	// Run golangci-lint if available
-	if commandExists("golangci-lint") {
-		lintResult := a.runGolangciLint(files)
-		results = append(results, lintResult)
-	}
+// SetLineFilter enables new-issues-only mode: diagnostics outside the
+// filtered lines are kept as informational and no longer fail their check
+func (a *Analyzer) SetLineFilter(fn LineFilter) {
+	a.changed = fn
+}
 
-	return results
+// RunChecks runs all applicable checks on the given files, killing any
+// running tool when ctx is cancelled
+func (a *Analyzer) RunChecks(ctx context.Context, files []string) []CheckResult {
+	return a.run(ctx, files, "")
 }
 
-func (a *Analyzer) runGoFmt(files []string) CheckResult {
-	// Check each file individually for formatting issues
-	cmd := exec.Command("gofmt", "-l")
-	cmd.Args = append(cmd.Args, files...)
-	cmd.Dir = a.repoPath
+// RunStage runs the applicable checks of one pipeline stage
+func (a *Analyzer) RunStage(ctx context.Context, files []string, stageName string) []CheckResult {
+	return a.run(ctx, files, stageName)
+}
 
-	output, err := cmd.Output()
-	outputStr := strings.TrimSpace(string(output))
+// Count returns how many checkers RunStage would run on files, or RunChecks
+// when stageName is empty
+func (a *Analyzer) Count(files []string, stageName string) int {
+	return len(a.plan(files, stageName))
+}
 
-	if err != nil || outputStr != "" {
-		errors := []string{}
-		if outputStr != "" {
-			errors = append(errors, "Files not formatted:\n"+outputStr)
+// run runs the checks of a stage, or of every stage when stageName is empty
+func (a *Analyzer) run(ctx context.Context, files []string, stageName string) []CheckResult {
+	results := make([]CheckResult, 0)
+	plan := a.plan(files, stageName)
+
+	for i, p := range plan {
+		next := p.checker.Run(ctx, a.repoPath, p.files)
+		for j := range next {
+			next[j].Blocking = blocking(p.checker)
+			if a.changed != nil {
+				next[j] = a.onlyNewIssues(next[j])
+			}
 		}
-		return CheckResult{
-			Tool:     "gofmt",
-			Severity: "error",
-			Message:  "Go formatting issues found",
-			Success:  false,
-			Output:   outputStr,
-			Errors:   errors,
+		results = append(results, next...)
+		if a.progress != nil {
+			a.progress(i+1, len(plan), next)
 		}
 	}
 
-	return CheckResult{
-		Tool:     "gofmt",
-		Severity: "info",
-		Message:  "All Go files properly formatted",
-		Success:  true,
-	}
+	return results
 }
 
-func (a *Analyzer) runGoVet() CheckResult {
-	cmd := exec.Command("go", "vet", "./...")
-	cmd.Dir = a.repoPath
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
-
-	if err != nil {
-		return CheckResult{
-			Tool:     "go vet",
-			Severity: "error",
-			Message:  "Go vet found issues",
-			Success:  false,
-			Output:   outputStr,
-			Errors:   []string{outputStr},
+// plan lists the available checkers of a stage that match any of files
+func (a *Analyzer) plan(files []string, stageName string) []plannedCheck {
+	plan := make([]plannedCheck, 0)
+	for _, checker := range a.registry.Checkers() {
+		if stageName != "" && stage(checker) != stageName {
+			continue
 		}
-	}
-
-	return CheckResult{
-		Tool:     "go vet",
-		Severity: "info",
-		Message:  "No issues found by go vet",
-		Success:  true,
-	}
-}
-
-func (a *Analyzer) runGolangciLint(files []string) CheckResult {
-	cmd := exec.Command("golangci-lint", "run", "--out-format=colored-line-number")
-	cmd.Dir = a.repoPath
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
-
-	if err != nil {
-		return CheckResult{
-			Tool:     "golangci-lint",
-			Severity: "error",
-			Message:  "Linter found issues",
-			Success:  false,
-			Output:   outputStr,
-			Errors:   []string{outputStr},
+		matched := make([]string, 0)
+		for _, file := range files {
+			if checker.Match(file) && fileExists(file) {
+				matched = append(matched, file)
+			}
+		}
+		if len(matched) > 0 && checker.Available() {
+			plan = append(plan, plannedCheck{checker: checker, files: matched})
 		}
 	}
-
-	return CheckResult{
-		Tool:     "golangci-lint",
-		Severity: "info",
-		Message:  "No linting issues found",
-		Success:  true,
-	}
+	return plan
 }
 
-func (a *Analyzer) checkDart(files []string) []CheckResult {
-	results := make([]CheckResult, 0)
-
-	// Check if dart is available
-	if !commandExists("dart") {
-		return results
+// onlyNewIssues downgrades the diagnostics outside the changed lines and
+// passes the check when none of its diagnostics are new
+func (a *Analyzer) onlyNewIssues(result CheckResult) CheckResult {
+	if result.Success || len(result.Diagnostics) == 0 {
+		return result
 	}
 
-	cmd := exec.Command("dart", "analyze")
-	cmd.Dir = a.repoPath
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
-
-	if err != nil {
-		results = append(results, CheckResult{
-			Tool:     "dart analyze",
-			Severity: "error",
-			Message:  "Dart analysis found issues",
-			Success:  false,
-			Output:   outputStr,
-			Errors:   []string{outputStr},
-		})
-	} else {
-		results = append(results, CheckResult{
-			Tool:     "dart analyze",
-			Severity: "info",
-			Message:  "No Dart issues found",
-			Success:  true,
-		})
-	}
-
-	// Check for Flutter
-	if commandExists("flutter") {
-		cmd = exec.Command("flutter", "analyze")
-		cmd.Dir = a.repoPath
-
-		output, err = cmd.CombinedOutput()
-		outputStr = strings.TrimSpace(string(output))
-
-		if err != nil {
-			results = append(results, CheckResult{
-				Tool:     "flutter analyze",
-				Severity: "error",
-				Message:  "Flutter analysis found issues",
-				Success:  false,
-				Output:   outputStr,
-				Errors:   []string{outputStr},
-			})
-		} else {
-			results = append(results, CheckResult{
-				Tool:     "flutter analyze",
-				Severity: "info",
-				Message:  "No Flutter issues found",
-				Success:  true,
-			})
+	fresh := make([]Diagnostic, 0, len(result.Diagnostics))
+	preexisting := 0
+	for i, d := range result.Diagnostics {
+		if a.changed(d.File, d.Line) {
+			fresh = append(fresh, d)
+			continue
 		}
+		result.Diagnostics[i].Severity = "info"
+		result.Diagnostics[i].Preexisting = true
+		preexisting++
 	}
-
-	return results
-}
-
-func (a *Analyzer) checkBash(files []string) []CheckResult {
-	results := make([]CheckResult, 0)
-
-	if !commandExists("shellcheck") {
-		return results
+	if preexisting == 0 {
+		return result
 	}
 
-	for _, file := range files {
-		cmd := exec.Command("shellcheck", "-f", "gcc", file)
-		output, err := cmd.CombinedOutput()
-		outputStr := strings.TrimSpace(string(output))
-
-		if err != nil {
-			results = append(results, CheckResult{
-				Tool:     "shellcheck",
-				File:     file,
-				Severity: "error",
-				Message:  "Shellcheck found issues",
-				Success:  false,
-				Output:   outputStr,
-				Errors:   []string{outputStr},
-			})
-		} else {
-			results = append(results, CheckResult{
-				Tool:     "shellcheck",
-				File:     file,
-				Severity: "info",
-				Message:  "No shell script issues",
-				Success:  true,
-			})
-		}
+	result.Errors = diagnosticStrings(fresh)
+	if len(fresh) > 0 {
+		result.File, result.Line, result.Column = fresh[0].File, fresh[0].Line, fresh[0].Column
+		result.Message = fmt.Sprintf("%s (%d pre-existing ignored)", result.Message, preexisting)
+		return result
 	}
 
-	return results
+	result.File, result.Line, result.Column = "", 0, 0
+	result.Severity = "info"
+	result.Message = fmt.Sprintf("No new issues on changed lines (%d pre-existing)", preexisting)
+	result.Success = true
+	return result
 }
 
-func (a *Analyzer) checkJavaScript(files []string) []CheckResult {
-	results := make([]CheckResult, 0)
-
-	if !commandExists("eslint") {
-		return results
-	}
-
-	cmd := exec.Command("eslint")
-	cmd.Args = append(cmd.Args, files...)
-	cmd.Dir = a.repoPath
-
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
-
-	if err != nil {
-		results = append(results, CheckResult{
-			Tool:     "eslint",
-			Severity: "error",
-			Message:  "ESLint found issues",
-			Success:  false,
-			Output:   outputStr,
-			Errors:   []string{outputStr},
-		})
-	} else {
-		results = append(results, CheckResult{
-			Tool:     "eslint",
-			Severity: "info",
-			Message:  "No ESLint issues found",
-			Success:  true,
-		})
-	}
-
-	return results
+// failedResult builds a failing CheckResult located at its first diagnostic
+func failedResult(tool, message, output string, diags []Diagnostic) CheckResult {
+	result := CheckResult{
+		Tool:        tool,
+		Severity:    "error",
+		Message:     message,
+		Success:     false,
+		Output:      output,
+		Errors:      diagnosticStrings(diags),
+		Diagnostics: diags,
+	}
+	if len(diags) == 0 {
+		result.Errors = []string{output}
+		return result
+	}
+
+	result.File = diags[0].File
+	result.Line = diags[0].Line
+	result.Column = diags[0].Column
+	return result
 }
 
 // ExplainFailure provides detailed explanation for a failure
@@ -340,6 +197,15 @@ func ExplainFailure(failureType, details string) string {
 		"flutter analyze": "Flutter analyzer found issues in your Flutter code. Review and fix them.",
 		"shellcheck":      "Shellcheck found issues in your shell scripts. Review the suggestions and fix critical issues.",
 		"eslint":          "ESLint found JavaScript/TypeScript issues. Run 'eslint --fix' to auto-fix some issues.",
+		"py_compile":      "Python files contain syntax errors. Fix the reported lines so the files compile.",
+		"ruff":            "Ruff found Python lint issues. Run 'ruff check --fix' to auto-fix some issues.",
+		"ruff format":     "Python files must be formatted with ruff. Run 'ruff format' to fix formatting issues.",
+		"mypy":            "Mypy found type errors in your Python code. Fix the annotations or the code they flag.",
+		"cargo fmt":       "Rust files must be formatted with rustfmt. Run 'cargo fmt --all' to fix formatting issues.",
+		"cargo clippy":    "Clippy found issues in your Rust code. Run 'cargo clippy --fix' to auto-fix some of them.",
+		"cargo check":     "Your Rust code does not compile. Fix the reported errors before pushing.",
+		"secrets":         "A secret or credential was added by an unpushed commit. Remove it from history (e.g. amend or rebase), rotate it, and load it from the environment instead. Mark false positives with 'guardian:allow-secret' or add them to .guardian-secrets-allowlist.",
+		"commit-policy":   "Commit messages don't follow the commit policy. Reword them with 'git rebase -i' (reword, or squash WIP and fixup! commits) before pushing.",
 		"test":            "Tests failed. Review the test output and fix failing tests before pushing.",
 	}
 
@@ -355,6 +221,19 @@ func ExplainFailure(failureType, details string) string {
 	return explanation
 }
 
+// absPath makes path absolute, leaving it unchanged if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
 func commandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
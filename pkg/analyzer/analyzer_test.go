@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeChecker reports one passing result per tool for the files it matches
+type fakeChecker struct {
+	name  string
+	ext   string
+	stage string
+	tools []string
+}
+
+func (c fakeChecker) Name() string           { return c.name }
+func (c fakeChecker) Match(file string) bool { return hasExtension(file, c.ext) }
+func (c fakeChecker) Available() bool        { return true }
+func (c fakeChecker) Stage() string          { return c.stage }
+
+func (c fakeChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	results := make([]CheckResult, 0, len(c.tools))
+	for _, tool := range c.tools {
+		results = append(results, CheckResult{Tool: tool, Success: true})
+	}
+	return results
+}
+
+func TestAnalyzerProgress(t *testing.T) {
+	dir := t.TempDir()
+	files := make([]string, 0)
+	for _, name := range []string{"a.fa", "b.fb", "c.fc"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	a := &Analyzer{repoPath: dir, registry: NewRegistry()}
+	a.Register(fakeChecker{name: "a", ext: ".fa", stage: "format", tools: []string{"a-fmt"}})
+	a.Register(fakeChecker{name: "b", ext: ".fb", stage: "lint", tools: []string{"b-vet", "b-lint"}})
+	a.Register(fakeChecker{name: "c", ext: ".fc", stage: "lint", tools: []string{"c-lint"}})
+	a.Register(fakeChecker{name: "unmatched", ext: ".none", stage: "lint", tools: []string{"none"}})
+
+	tests := []struct {
+		name  string
+		stage string
+		want  int
+	}{
+		{"all stages", "", 3},
+		{"format", "format", 1},
+		{"lint", "lint", 2},
+		{"empty stage", "build", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Count(files, tt.stage); got != tt.want {
+				t.Fatalf("got count %d, want %d", got, tt.want)
+			}
+
+			calls := 0
+			a.SetProgress(func(index, total int, results []CheckResult) {
+				calls++
+				if index != calls || total != tt.want || len(results) == 0 {
+					t.Errorf("got progress %d/%d with %d results, want %d/%d", index, total, len(results), calls, tt.want)
+				}
+			})
+			a.RunStage(context.Background(), files, tt.stage)
+			if calls != tt.want {
+				t.Errorf("got %d progress calls, want %d", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistryStages(t *testing.T) {
+	formatters := map[string]bool{"go-format": true, "python-format": true, "rust-format": true, "data": true}
+	for _, c := range DefaultRegistry().Checkers() {
+		want := "lint"
+		if formatters[c.Name()] {
+			want = "format"
+		}
+		if got := stage(c); got != want {
+			t.Errorf("got stage %s for %s, want %s", got, c.Name(), want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// Checker runs the static checks for one language or tool
+type Checker interface {
+	// Name identifies the checker, e.g. "go" or "shellcheck"
+	Name() string
+	// Match reports whether the checker handles a file
+	Match(file string) bool
+	// Available reports whether the tools the checker needs are installed
+	Available() bool
+	// Run checks the matched files, stopping any running tool when ctx is cancelled
+	Run(ctx context.Context, repoPath string, files []string) []CheckResult
+}
+
+// BlockingChecker is implemented by checkers whose failures may be advisory;
+// checkers that don't implement it always block
+type BlockingChecker interface {
+	Checker
+	Blocking() bool
+}
+
+// blocking reports whether a checker's failures fail validation
+func blocking(c Checker) bool {
+	if b, ok := c.(BlockingChecker); ok {
+		return b.Blocking()
+	}
+	return true
+}
+
+// StagedChecker is implemented by checkers that run in a pipeline stage
+// other than lint
+type StagedChecker interface {
+	Checker
+	Stage() string
+}
+
+// stage returns the pipeline stage a checker runs in
+func stage(c Checker) string {
+	if s, ok := c.(StagedChecker); ok && s.Stage() != "" {
+		return s.Stage()
+	}
+	return config.StageLint
+}
+
+// Registry holds the checkers RunChecks dispatches to, in registration order
+type Registry struct {
+	checkers []Checker
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry creates a registry with the built-in checkers
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(goFormatChecker{})
+	r.Register(goChecker{})
+	r.Register(dartChecker{})
+	r.Register(shellChecker{})
+	r.Register(javaScriptChecker{})
+	r.Register(pythonFormatChecker{})
+	r.Register(pythonChecker{})
+	r.Register(rustFormatChecker{})
+	r.Register(rustChecker{})
+	r.Register(dataChecker{})
+	return r
+}
+
+// Register adds a checker; a checker with the same name is replaced in place
+func (r *Registry) Register(c Checker) {
+	for i, existing := range r.checkers {
+		if existing.Name() == c.Name() {
+			r.checkers[i] = c
+			return
+		}
+	}
+	r.checkers = append(r.checkers, c)
+}
+
+// Checkers returns the registered checkers in registration order
+func (r *Registry) Checkers() []Checker {
+	return append([]Checker(nil), r.checkers...)
+}
+
+// hasExtension reports whether a file ends in one of the given extensions
+func hasExtension(file string, exts ...string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// projectTool describes a tool run once per project directory
+type projectTool struct {
+	name    string
+	failure string
+	success string
+	command func(files []string) []string
+	parse   func(stdout []byte, combined, dir string) []Diagnostic
+}
+
+// runProjectTool runs a tool in each project directory and merges the findings
+func runProjectTool(ctx context.Context, projects map[string][]string, tool projectTool) CheckResult {
+	diags := make([]Diagnostic, 0)
+	outputs := make([]string, 0, len(projects))
+	failed := false
+
+	for _, dir := range sortedKeys(projects) {
+		argv := tool.command(projects[dir])
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Dir = dir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		combined := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+		if combined != "" {
+			outputs = append(outputs, combined)
+		}
+		found := tool.parse(stdout.Bytes(), combined, dir)
+		diags = append(diags, found...)
+		if err != nil || HasErrors(found) {
+			failed = true
+		}
+	}
+
+	if failed {
+		return failedResult(tool.name, tool.failure, strings.Join(outputs, "\n"), diags)
+	}
+	return CheckResult{
+		Tool:     tool.name,
+		Severity: "info",
+		Message:  tool.success,
+		Success:  true,
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
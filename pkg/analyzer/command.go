@@ -0,0 +1,197 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// CommandChecker runs a check declared in the checks section of .mcp.yml
+type CommandChecker struct {
+	cfg      config.CheckConfig
+	repoPath string
+	argv     []string // program and arguments; sh -c for shell mode
+	env      []string // NAME=value prefix of an argv-mode command
+	patterns []*regexp.Regexp
+	pattern  *regexp.Regexp
+}
+
+// NewCommandChecker creates a checker for a configured check; patterns are
+// matched against paths relative to repoPath
+func NewCommandChecker(repoPath string, cfg config.CheckConfig) (*CommandChecker, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := &CommandChecker{cfg: cfg, repoPath: repoPath}
+	if cfg.Mode == config.ModeShell {
+		// Matched files follow as the script's positional parameters, "$@"
+		c.argv = []string{"sh", "-c", cfg.Command, "sh"}
+	} else {
+		argv, env, err := cfg.Argv()
+		if err != nil {
+			return nil, err
+		}
+		c.argv, c.env = argv, env
+	}
+	for _, glob := range cfg.Patterns {
+		re, err := globRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' for check '%s': %w", glob, cfg.Name, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	if cfg.Parser == config.ParserRegex {
+		c.pattern = regexp.MustCompile(cfg.Pattern)
+	}
+	return c, nil
+}
+
+// Name returns the configured check name
+func (c *CommandChecker) Name() string {
+	return c.cfg.Name
+}
+
+// Match reports whether a file matches one of the check's patterns; a check
+// without patterns applies to every file
+func (c *CommandChecker) Match(file string) bool {
+	if len(c.patterns) == 0 {
+		return true
+	}
+
+	rel := file
+	if root, err := filepath.Abs(c.repoPath); err == nil {
+		if r, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, re := range c.patterns {
+		if re.MatchString(rel) || re.MatchString(filepath.Base(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Available reports whether the command's executable can be found
+func (c *CommandChecker) Available() bool {
+	return commandExists(c.argv[0])
+}
+
+// Blocking reports whether a failure of the check fails validation
+func (c *CommandChecker) Blocking() bool {
+	return c.cfg.Blocking
+}
+
+// Stage returns the pipeline stage the check runs in
+func (c *CommandChecker) Stage() string {
+	return c.cfg.Stage
+}
+
+// Run executes the command and parses its output into diagnostics
+func (c *CommandChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	args := append([]string{}, c.argv[1:]...)
+	if c.cfg.PassFiles {
+		args = append(args, files...)
+	}
+
+	cmd := exec.CommandContext(ctx, c.argv[0], args...)
+	cmd.Dir = repoPath
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	combined := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+	if ctx.Err() == context.DeadlineExceeded {
+		return []CheckResult{failedResult(c.cfg.Name,
+			fmt.Sprintf("Check timed out after %d seconds", c.cfg.Timeout), combined, nil)}
+	}
+
+	diags, parseErr := c.parse(stdout.Bytes(), combined, repoPath)
+	if err == nil && !HasErrors(diags) {
+		message := fmt.Sprintf("No issues found by %s", c.cfg.Name)
+		if len(diags) > 0 {
+			message = fmt.Sprintf("No errors found by %s (%d findings)", c.cfg.Name, len(diags))
+		}
+		return []CheckResult{{
+			Tool:        c.cfg.Name,
+			Severity:    "info",
+			Message:     message,
+			Success:     true,
+			Output:      combined,
+			Diagnostics: diags,
+		}}
+	}
+
+	result := failedResult(c.cfg.Name, fmt.Sprintf("%s found issues", c.cfg.Name), combined, diags)
+	if parseErr != nil {
+		result.Errors = append(result.Errors, parseErr.Error())
+	}
+	return []CheckResult{result}
+}
+
+// parse applies the configured parser; structured formats read stdout only
+func (c *CommandChecker) parse(stdout []byte, combined, dir string) ([]Diagnostic, error) {
+	switch c.cfg.Parser {
+	case config.ParserJSON:
+		if len(bytes.TrimSpace(stdout)) == 0 {
+			return nil, nil
+		}
+		return parseJSONDiagnostics(stdout, dir, c.cfg.Severity)
+	case config.ParserSARIF:
+		if len(bytes.TrimSpace(stdout)) == 0 {
+			return nil, nil
+		}
+		return parseSARIF(stdout, dir, c.cfg.Severity)
+	case config.ParserRegex:
+		return parseRegex(combined, c.pattern, dir, c.cfg.Severity), nil
+	case config.ParserNone:
+		return nil, nil
+	}
+	return parseGCCStyle(combined, dir, c.cfg.Severity), nil
+}
+
+// globRegexp converts a glob with ** support into an anchored regexp
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
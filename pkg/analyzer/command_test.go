@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+func TestCommandChecker_Run(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("grep not installed")
+	}
+	dir := t.TempDir()
+	marked := filepath.Join(dir, "marked.go")
+	clean := filepath.Join(dir, "clean.go")
+	if err := os.WriteFile(marked, []byte("package a\n\n// FIXME: later\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clean, []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := `^(?P<file>[^:]+):(?P<line>\d+):(?P<message>.*)$`
+	tests := []struct {
+		name      string
+		cfg       config.CheckConfig
+		file      string
+		wantOK    bool
+		wantDiags int
+	}{
+		{"quoted argument", config.CheckConfig{Command: `grep -Hn "FIXME: later"`}, marked, false, 1},
+		{"quoted argument without match", config.CheckConfig{Command: `grep -Hn "FIXME: later"`}, clean, false, 0},
+		{"shell mode with match", config.CheckConfig{Command: `grep -Hn FIXME "$@" || [ $? -eq 1 ]`, Mode: config.ModeShell}, marked, false, 1},
+		{"shell mode without match", config.CheckConfig{Command: `grep -Hn FIXME "$@" || [ $? -eq 1 ]`, Mode: config.ModeShell}, clean, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Name, cfg.PassFiles, cfg.Parser, cfg.Pattern, cfg.Severity, cfg.Timeout = "todo", true, config.ParserRegex, pattern, "error", 10
+			checker, err := NewCommandChecker(dir, cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			results := checker.Run(context.Background(), dir, []string{tt.file})
+			if len(results) != 1 || results[0].Success != tt.wantOK || len(results[0].Diagnostics) != tt.wantDiags {
+				t.Errorf("got %+v, want success %v with %d diagnostics", results, tt.wantOK, tt.wantDiags)
+			}
+		})
+	}
+}
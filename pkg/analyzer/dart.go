@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// dartChecker runs dart analyze and, when installed, flutter analyze
+type dartChecker struct{}
+
+func (dartChecker) Name() string { return "dart" }
+
+func (dartChecker) Match(file string) bool { return hasExtension(file, ".dart") }
+
+func (dartChecker) Available() bool { return commandExists("dart") }
+
+func (dartChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	results := make([]CheckResult, 0)
+
+	cmd := exec.CommandContext(ctx, "dart", "analyze")
+	cmd.Dir = repoPath
+
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+
+	if err != nil {
+		results = append(results, CheckResult{
+			Tool:     "dart analyze",
+			Severity: "error",
+			Message:  "Dart analysis found issues",
+			Success:  false,
+			Output:   outputStr,
+			Errors:   []string{outputStr},
+		})
+	} else {
+		results = append(results, CheckResult{
+			Tool:     "dart analyze",
+			Severity: "info",
+			Message:  "No Dart issues found",
+			Success:  true,
+		})
+	}
+
+	// Check for Flutter
+	if commandExists("flutter") {
+		cmd = exec.CommandContext(ctx, "flutter", "analyze")
+		cmd.Dir = repoPath
+
+		output, err = cmd.CombinedOutput()
+		outputStr = strings.TrimSpace(string(output))
+
+		if err != nil {
+			results = append(results, CheckResult{
+				Tool:     "flutter analyze",
+				Severity: "error",
+				Message:  "Flutter analysis found issues",
+				Success:  false,
+				Output:   outputStr,
+				Errors:   []string{outputStr},
+			})
+		} else {
+			results = append(results, CheckResult{
+				Tool:     "flutter analyze",
+				Severity: "info",
+				Message:  "No Flutter issues found",
+				Success:  true,
+			})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,412 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// dataChecker parses YAML, JSON and TOML files in-process, reporting syntax
+// errors and duplicate keys, and validates the guardian's own .mcp.yml
+type dataChecker struct{}
+
+func (dataChecker) Name() string { return "data" }
+
+func (dataChecker) Match(file string) bool {
+	return hasExtension(file, ".yaml", ".yml", ".json", ".toml")
+}
+
+func (dataChecker) Available() bool { return true }
+
+// Stage runs syntax checks of data files first, with the formatters
+func (dataChecker) Stage() string { return config.StageFormat }
+
+func (dataChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	formats := []struct {
+		tool  string
+		exts  []string
+		check func(file string, data []byte) []Diagnostic
+	}{
+		{"yaml", []string{".yaml", ".yml"}, checkYAML},
+		{"json", []string{".json"}, checkJSON},
+		{"toml", []string{".toml"}, checkTOML},
+	}
+
+	results := make([]CheckResult, 0)
+	for _, format := range formats {
+		diags := make([]Diagnostic, 0)
+		checked := 0
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return results
+			}
+			if !hasExtension(file, format.exts...) {
+				continue
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				diags = append(diags, Diagnostic{File: file, Message: err.Error(), Severity: "error"})
+				continue
+			}
+			diags = append(diags, format.check(file, data)...)
+			checked++
+		}
+		if checked == 0 && len(diags) == 0 {
+			continue
+		}
+
+		if len(diags) > 0 {
+			results = append(results, failedResult(format.tool, fmt.Sprintf("Invalid %s files found", strings.ToUpper(format.tool)), "", diags))
+			continue
+		}
+		results = append(results, CheckResult{
+			Tool:     format.tool,
+			Severity: "info",
+			Message:  fmt.Sprintf("All %s files are valid", strings.ToUpper(format.tool)),
+			Success:  true,
+		})
+	}
+	return results
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// templateAction matches a Go or Jinja template action; GitHub Actions
+// expressions, ${{ ... }}, are plain YAML strings and don't count
+var templateAction = regexp.MustCompile(`(^|[^$]){[{%]`)
+
+// checkYAML parses every document of a YAML file. Helm chart templates are
+// skipped, as are other templated files that don't parse as plain YAML
+func checkYAML(file string, data []byte) []Diagnostic {
+	templated := templateAction.Match(data)
+	if templated && isHelmTemplate(file) {
+		return nil
+	}
+
+	diags := make([]Diagnostic, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && templated {
+			return nil
+		}
+		if err != nil {
+			return append(diags, yamlErrorDiagnostics(file, err, "syntax")...)
+		}
+		diags = append(diags, yamlDuplicateKeys(file, &doc)...)
+	}
+
+	if len(diags) == 0 && isGuardianConfig(file) {
+		diags = append(diags, checkGuardianConfig(file, data)...)
+	}
+	return diags
+}
+
+// yamlErrorDiagnostics splits a yaml.v3 error into one diagnostic per "line N: msg"
+func yamlErrorDiagnostics(file string, err error, rule string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, m := range yamlErrorLine.FindAllStringSubmatch(err.Error(), -1) {
+		line, _ := strconv.Atoi(m[1])
+		diags = append(diags, Diagnostic{File: file, Line: line, Rule: rule, Message: m[2], Severity: "error"})
+	}
+	if len(diags) == 0 {
+		diags = append(diags, Diagnostic{
+			File:     file,
+			Rule:     rule,
+			Message:  strings.TrimPrefix(err.Error(), "yaml: "),
+			Severity: "error",
+		})
+	}
+	return diags
+}
+
+// yamlDuplicateKeys walks a document reporting keys repeated in a mapping
+func yamlDuplicateKeys(file string, node *yaml.Node) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	if node.Kind == yaml.MappingNode {
+		seen := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode || key.Value == "<<" {
+				continue
+			}
+			if first, ok := seen[key.Value]; ok {
+				diags = append(diags, Diagnostic{
+					File:     file,
+					Line:     key.Line,
+					Column:   key.Column,
+					Rule:     "duplicate-key",
+					Message:  fmt.Sprintf("key %q already defined at line %d", key.Value, first.Line),
+					Severity: "error",
+				})
+				continue
+			}
+			seen[key.Value] = key
+		}
+	}
+	for _, child := range node.Content {
+		diags = append(diags, yamlDuplicateKeys(file, child)...)
+	}
+	return diags
+}
+
+// isHelmTemplate reports whether a file is in a chart's templates directory
+func isHelmTemplate(file string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(file)), "/") {
+		if part == "templates" {
+			return true
+		}
+	}
+	return false
+}
+
+func isGuardianConfig(file string) bool {
+	name := filepath.Base(file)
+	return name == ".mcp.yml" || name == ".mcp.yaml"
+}
+
+// checkGuardianConfig applies the config.Config schema to a .mcp.yml
+func checkGuardianConfig(file string, data []byte) []Diagnostic {
+	cfg, err := config.ParseStrict(data)
+	if err != nil {
+		return yamlErrorDiagnostics(file, err, "config")
+	}
+	if err := cfg.Validate(); err != nil {
+		return []Diagnostic{{File: file, Rule: "config", Message: err.Error(), Severity: "error"}}
+	}
+	return nil
+}
+
+// jsonFrame tracks an open JSON object or array while tokenizing
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	keys      map[string]int64
+}
+
+// jsonChecker holds the state of one checkJSON run
+type jsonChecker struct {
+	file  string
+	data  []byte
+	stack []*jsonFrame
+	diags []Diagnostic
+}
+
+// checkJSON tokenizes a JSON document to report syntax errors and duplicate
+// object keys, neither of which encoding/json's Unmarshal surfaces with a position.
+// Comments and trailing commas are allowed in files known to be JSONC
+func checkJSON(file string, data []byte) []Diagnostic {
+	if isJSONC(file) {
+		data = stripJSONC(data)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []Diagnostic{{File: file, Rule: "syntax", Message: "empty JSON document", Severity: "error"}}
+	}
+
+	c := &jsonChecker{file: file, data: data, diags: make([]Diagnostic, 0)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	for {
+		before := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return append(c.diags, c.syntaxError(err, before))
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			c.delim(t)
+		case string:
+			c.str(t, before)
+		default:
+			c.value()
+		}
+
+		if len(c.stack) == 0 && decoder.More() {
+			return append(c.diags, c.diagnostic(decoder.InputOffset(), "syntax", "unexpected data after top-level value"))
+		}
+	}
+	return c.diags
+}
+
+// diagnostic reports an error at a byte offset
+func (c *jsonChecker) diagnostic(offset int64, rule, message string) Diagnostic {
+	line, column := offsetPosition(c.data, offset)
+	return Diagnostic{File: c.file, Line: line, Column: column, Rule: rule, Message: message, Severity: "error"}
+}
+
+// syntaxError reports a tokenizer error at the offending byte, or at the
+// token's start when the error has no offset
+func (c *jsonChecker) syntaxError(err error, before int64) Diagnostic {
+	offset := before
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+		offset = syntaxErr.Offset - 1 // the offset counts the offending byte
+	}
+	return c.diagnostic(offset, "syntax", strings.TrimPrefix(err.Error(), "json: "))
+}
+
+// delim opens or closes an object or array
+func (c *jsonChecker) delim(d json.Delim) {
+	switch d {
+	case '{':
+		c.stack = append(c.stack, &jsonFrame{object: true, expectKey: true, keys: make(map[string]int64)})
+	case '[':
+		c.stack = append(c.stack, &jsonFrame{})
+	default:
+		c.stack = c.stack[:len(c.stack)-1]
+		c.value()
+	}
+}
+
+// str handles a string, which is either an object key or a value
+func (c *jsonChecker) str(s string, before int64) {
+	top := c.top()
+	if top == nil || !top.object || !top.expectKey {
+		c.value()
+		return
+	}
+
+	top.expectKey = false
+	start := keyOffset(c.data, before)
+	first, ok := top.keys[s]
+	if !ok {
+		top.keys[s] = start
+		return
+	}
+	firstLine, _ := offsetPosition(c.data, first)
+	c.diags = append(c.diags, c.diagnostic(start, "duplicate-key", fmt.Sprintf("key %q already defined at line %d", s, firstLine)))
+}
+
+// value records that the enclosing object expects its next key
+func (c *jsonChecker) value() {
+	if top := c.top(); top != nil && top.object {
+		top.expectKey = true
+	}
+}
+
+func (c *jsonChecker) top() *jsonFrame {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// isJSONC reports whether a .json file is read as JSON with comments, as
+// TypeScript and VS Code do for their config files
+func isJSONC(file string) bool {
+	name := filepath.Base(file)
+	dir := filepath.Base(filepath.Dir(file))
+	return dir == ".vscode" || dir == ".devcontainer" || name == "devcontainer.json" ||
+		strings.HasPrefix(name, "tsconfig") || strings.HasPrefix(name, "jsconfig")
+}
+
+// stripJSONC blanks out comments and trailing commas, keeping newlines so
+// positions still match the file
+func stripJSONC(data []byte) []byte {
+	out := append([]byte{}, data...)
+	comma := -1 // last comma not yet followed by a value
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			comma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				return out // unterminated, left for the tokenizer to report
+			}
+			blank(out[i : i+end+4])
+			i += end + 3
+		case out[i] == ',':
+			comma = i
+		case out[i] == '}' || out[i] == ']':
+			if comma >= 0 {
+				out[comma] = ' '
+			}
+			comma = -1
+		case strings.IndexByte(" \t\r\n", out[i]) < 0:
+			comma = -1
+		}
+	}
+	return out
+}
+
+// blank replaces everything but newlines with spaces
+func blank(b []byte) {
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
+
+// keyOffset skips the separators between the previous token and a key
+func keyOffset(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	prefix := data[:offset]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	column := len(prefix) - bytes.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
+// checkTOML parses a TOML document; the parser rejects duplicate keys itself
+func checkTOML(file string, data []byte) []Diagnostic {
+	var doc map[string]interface{}
+	_, err := toml.Decode(string(data), &doc)
+	if err == nil {
+		return nil
+	}
+
+	d := Diagnostic{File: file, Rule: "syntax", Message: strings.TrimPrefix(err.Error(), "toml: "), Severity: "error"}
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		d.Line, d.Column = offsetPosition(data, int64(parseErr.Position.Start))
+		if parseErr.Message != "" {
+			d.Message = parseErr.Message
+		}
+		if strings.Contains(d.Message, "already") {
+			d.Rule = "duplicate-key"
+		}
+	}
+	return []Diagnostic{d}
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want []Diagnostic
+	}{
+		{"valid", "a.json", `{"a": [1, {"b": null}], "c": "x"}`, []Diagnostic{}},
+		{"duplicate key", "a.json", "{\n  \"a\": 1,\n  \"a\": 2\n}",
+			[]Diagnostic{{File: "a.json", Line: 3, Column: 3, Rule: "duplicate-key", Message: `key "a" already defined at line 2`, Severity: "error"}}},
+		{"same key in sibling objects", "a.json", `[{"a": 1}, {"a": 2}]`, []Diagnostic{}},
+		{"syntax error", "a.json", "{\n  \"a\": 1,\n}",
+			[]Diagnostic{{File: "a.json", Line: 2, Column: 9, Rule: "syntax", Message: "invalid character ',' looking for beginning of value", Severity: "error"}}},
+		{"trailing data", "a.json", `{} {}`,
+			[]Diagnostic{{File: "a.json", Line: 1, Column: 4, Rule: "syntax", Message: "unexpected data after top-level value", Severity: "error"}}},
+		{"empty", "a.json", " \n",
+			[]Diagnostic{{File: "a.json", Rule: "syntax", Message: "empty JSON document", Severity: "error"}}},
+		{"comments outside JSONC", "a.json", "// c\n{}",
+			[]Diagnostic{{File: "a.json", Line: 1, Column: 1, Rule: "syntax", Message: "invalid character '/' looking for beginning of value", Severity: "error"}}},
+		{"tsconfig comments and trailing comma", "web/tsconfig.app.json",
+			"{\n  // options\n  \"compilerOptions\": {\"strict\": true, /* \"x\": 1 */},\n  \"include\": [\"src/**/*\", \"a//b\",],\n}", []Diagnostic{}},
+		{"vscode settings duplicate key", ".vscode/settings.json", "{\n  /* a */ \"a\": 1,\n  \"a\": 2, // again\n}",
+			[]Diagnostic{{File: ".vscode/settings.json", Line: 3, Column: 3, Rule: "duplicate-key", Message: `key "a" already defined at line 2`, Severity: "error"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkJSON(tt.file, []byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckYAML(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		data      string
+		wantRules []string
+	}{
+		{"valid", "a.yml", "a: 1\nb: [1, 2]\n", nil},
+		{"duplicate key", "a.yml", "a: 1\na: 2\n", []string{"duplicate-key"}},
+		{"syntax error", "a.yml", "a: [1\n", []string{"syntax"}},
+		{"workflow expression is checked", ".github/workflows/ci.yml",
+			"on: push\njobs:\n  test:\n    if: ${{ github.ref == 'refs/heads/main' }}\n    runs-on: ubuntu-latest\n    runs-on: macos-latest\n", []string{"duplicate-key"}},
+		{"workflow syntax error", ".github/workflows/ci.yml", "env:\n  A: ${{ secrets.A }}\n  - b\n", []string{"syntax"}},
+		{"helm template", "chart/templates/deploy.yaml", "metadata:\n  name: {{ .Release.Name }}\n  name: x\n", nil},
+		{"unparseable template elsewhere", "ansible/site.yml", "{% if x %}\na: 1\n{% endif %}\n", nil},
+		{"template that parses is checked", "conf/app.yml", "a: \"{{ x }}\"\na: 2\n", []string{"duplicate-key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := make([]string, 0)
+			for _, d := range checkYAML(tt.file, []byte(tt.data)) {
+				rules = append(rules, d.Rule)
+			}
+			if len(rules) != len(tt.wantRules) || (len(rules) > 0 && !reflect.DeepEqual(rules, tt.wantRules)) {
+				t.Errorf("got rules %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
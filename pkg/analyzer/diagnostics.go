@@ -0,0 +1,444 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a single finding reported by a check
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+
+	// Commit is the commit that introduced the finding, when known
+	Commit string `json:"commit,omitempty"`
+
+	// Preexisting marks findings outside the changed lines in new-issues-only mode
+	Preexisting bool `json:"preexisting,omitempty"`
+}
+
+// String formats the diagnostic as file:line:col: message [rule]
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	b.WriteString(d.File)
+	if d.File == "" && d.Commit != "" {
+		fmt.Fprintf(&b, "commit %.8s", d.Commit)
+	}
+	if d.Line > 0 {
+		fmt.Fprintf(&b, ":%d", d.Line)
+		if d.Column > 0 {
+			fmt.Fprintf(&b, ":%d", d.Column)
+		}
+	}
+	fmt.Fprintf(&b, ": %s", d.Message)
+	if d.Rule != "" {
+		fmt.Fprintf(&b, " [%s]", d.Rule)
+	}
+	return b.String()
+}
+
+// diagnosticStrings renders diagnostics for CheckResult.Errors
+func diagnosticStrings(diags []Diagnostic) []string {
+	lines := make([]string, 0, len(diags))
+	for _, d := range diags {
+		lines = append(lines, d.String())
+	}
+	return lines
+}
+
+// HasErrors reports whether any diagnostic has error severity
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath makes a tool-reported path absolute relative to the directory it ran in
+func resolvePath(dir, file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	if abs, err := filepath.Abs(filepath.Join(dir, file)); err == nil {
+		return abs
+	}
+	return filepath.Join(dir, file)
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// parseGofmtDiff turns `gofmt -d` output into one diagnostic per hunk,
+// located at the first line gofmt would change
+func parseGofmtDiff(output, dir string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	file := ""
+	line := 0
+	inHunk := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = resolvePath(dir, strings.TrimSpace(strings.TrimPrefix(text, "+++ ")))
+			inHunk = false
+		case strings.HasPrefix(text, "@@"):
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				continue
+			}
+			line, _ = strconv.Atoi(m[1])
+			inHunk = true
+		case inHunk && strings.HasPrefix(text, " "):
+			line++
+		case inHunk && (strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+")):
+			diags = append(diags, Diagnostic{
+				File:     file,
+				Line:     line,
+				Rule:     "gofmt",
+				Message:  "file is not gofmt-formatted",
+				Severity: "error",
+			})
+			inHunk = false
+		}
+	}
+
+	return diags
+}
+
+// vetFinding is one entry of `go vet -json` output
+type vetFinding struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// parseVetJSON decodes the JSON objects `go vet -json` writes per package.
+// Packages that fail to type-check are reported as plain text instead,
+// which is parsed as gcc-style output.
+func parseVetJSON(output, dir string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+
+	var jsonPart, textPart strings.Builder
+	depth := 0
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if depth == 0 && !strings.HasPrefix(trimmed, "{") {
+			if !strings.HasPrefix(trimmed, "#") {
+				textPart.WriteString(strings.TrimPrefix(trimmed, "vet: ") + "\n")
+			}
+			continue
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		jsonPart.WriteString(line + "\n")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(jsonPart.String()))
+	for decoder.More() {
+		var packages map[string]map[string]json.RawMessage
+		if err := decoder.Decode(&packages); err != nil {
+			break
+		}
+		for _, analyzers := range packages {
+			for name, raw := range analyzers {
+				var findings []vetFinding
+				if err := json.Unmarshal(raw, &findings); err != nil {
+					continue
+				}
+				for _, finding := range findings {
+					d := parsePosition(finding.Posn, dir)
+					d.Rule = name
+					d.Message = finding.Message
+					d.Severity = "error"
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+
+	return append(diags, parseGCCStyle(textPart.String(), dir, "error")...)
+}
+
+// parsePosition parses a file:line:col position
+func parsePosition(posn, dir string) Diagnostic {
+	parts := strings.Split(posn, ":")
+	d := Diagnostic{File: resolvePath(dir, posn)}
+	if len(parts) >= 3 {
+		d.File = resolvePath(dir, strings.Join(parts[:len(parts)-2], ":"))
+		d.Line, _ = strconv.Atoi(parts[len(parts)-2])
+		d.Column, _ = strconv.Atoi(parts[len(parts)-1])
+	}
+	return d
+}
+
+// golangciReport is the JSON output format of golangci-lint
+type golangciReport struct {
+	Issues []struct {
+		FromLinter string `json:"FromLinter"`
+		Text       string `json:"Text"`
+		Severity   string `json:"Severity"`
+		Pos        struct {
+			Filename string `json:"Filename"`
+			Line     int    `json:"Line"`
+			Column   int    `json:"Column"`
+		} `json:"Pos"`
+	} `json:"Issues"`
+}
+
+// parseGolangciJSON decodes golangci-lint's JSON report
+func parseGolangciJSON(output []byte, dir string) ([]Diagnostic, error) {
+	start := bytes.IndexByte(output, '{')
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON report in golangci-lint output")
+	}
+
+	var report golangciReport
+	if err := json.NewDecoder(bytes.NewReader(output[start:])).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse golangci-lint report: %w", err)
+	}
+
+	diags := make([]Diagnostic, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		severity := strings.ToLower(issue.Severity)
+		if severity == "" {
+			severity = "error"
+		}
+		diags = append(diags, Diagnostic{
+			File:     resolvePath(dir, issue.Pos.Filename),
+			Line:     issue.Pos.Line,
+			Column:   issue.Pos.Column,
+			Rule:     issue.FromLinter,
+			Message:  issue.Text,
+			Severity: severity,
+		})
+	}
+	return diags, nil
+}
+
+var gccLine = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)?\s*(?:(error|warning|note|info|fatal error):\s*)?(.*?)(?:\s+\[([\w.-]+)\])?$`)
+
+// parseGCCStyle parses file:line[:col]: [severity:] message [rule] lines
+func parseGCCStyle(output, dir, defaultSeverity string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, line := range strings.Split(output, "\n") {
+		m := gccLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		d := Diagnostic{
+			File:     resolvePath(dir, m[1]),
+			Message:  m[5],
+			Rule:     m[6],
+			Severity: normalizeSeverity(m[4], defaultSeverity),
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+func normalizeSeverity(severity, fallback string) string {
+	switch strings.ToLower(severity) {
+	case "error", "fatal error", "fatal":
+		return "error"
+	case "warning", "warn":
+		return "warning"
+	case "note", "info", "style", "hint":
+		return "info"
+	}
+	return fallback
+}
+
+// parseRegex parses each output line with a pattern whose named groups
+// (file, line, column, severity, rule, message) fill in the diagnostic
+func parseRegex(output string, re *regexp.Regexp, dir, defaultSeverity string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, line := range strings.Split(output, "\n") {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		d := Diagnostic{Severity: defaultSeverity}
+		for i, name := range re.SubexpNames() {
+			switch name {
+			case "file":
+				d.File = resolvePath(dir, m[i])
+			case "line":
+				d.Line, _ = strconv.Atoi(m[i])
+			case "column":
+				d.Column, _ = strconv.Atoi(m[i])
+			case "severity":
+				d.Severity = normalizeSeverity(m[i], defaultSeverity)
+			case "rule":
+				d.Rule = m[i]
+			case "message":
+				d.Message = strings.TrimSpace(m[i])
+			}
+		}
+		if d.Message == "" {
+			d.Message = strings.TrimSpace(line)
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// Field names accepted by the json parser, covering the common linter formats
+var (
+	jsonFileKeys     = []string{"file", "path", "filename", "filepath", "filePath"}
+	jsonLineKeys     = []string{"line", "start_line", "line_no", "startLine"}
+	jsonColumnKeys   = []string{"column", "col", "start_column", "line_pos", "startColumn"}
+	jsonRuleKeys     = []string{"rule", "code", "ruleId", "rule_id", "check", "type"}
+	jsonMessageKeys  = []string{"message", "description", "text", "msg"}
+	jsonSeverityKeys = []string{"severity", "level"}
+	jsonNestedKeys   = []string{"violations", "messages", "issues", "diagnostics"}
+)
+
+// parseJSONDiagnostics parses a JSON array, a stream of JSON objects or an
+// object wrapping an issue list; objects holding a nested list of findings
+// (e.g. {"filepath": ..., "violations": [...]}) lend their file to them
+func parseJSONDiagnostics(output []byte, dir, defaultSeverity string) ([]Diagnostic, error) {
+	diags := make([]Diagnostic, 0)
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return diags, fmt.Errorf("failed to parse JSON output: %w", err)
+		}
+		diags = appendJSONDiagnostics(diags, value, "", dir, defaultSeverity)
+	}
+	return diags, nil
+}
+
+func appendJSONDiagnostics(diags []Diagnostic, value interface{}, file, dir, defaultSeverity string) []Diagnostic {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			diags = appendJSONDiagnostics(diags, item, file, dir, defaultSeverity)
+		}
+	case map[string]interface{}:
+		if name := jsonString(v, jsonFileKeys); name != "" {
+			file = name
+		}
+		nested := false
+		for _, key := range jsonNestedKeys {
+			if list, ok := v[key].([]interface{}); ok {
+				diags = appendJSONDiagnostics(diags, list, file, dir, defaultSeverity)
+				nested = true
+			}
+		}
+		message := jsonString(v, jsonMessageKeys)
+		if nested || message == "" {
+			return diags
+		}
+		diags = append(diags, Diagnostic{
+			File:     resolvePath(dir, file),
+			Line:     jsonInt(v, jsonLineKeys),
+			Column:   jsonInt(v, jsonColumnKeys),
+			Rule:     jsonString(v, jsonRuleKeys),
+			Message:  message,
+			Severity: jsonSeverity(v, defaultSeverity),
+		})
+	}
+	return diags
+}
+
+func jsonString(v map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if s, ok := v[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func jsonInt(v map[string]interface{}, keys []string) int {
+	for _, key := range keys {
+		if n, ok := v[key].(float64); ok {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// jsonSeverity accepts names as well as ESLint-style numbers (2 error, 1 warning)
+func jsonSeverity(v map[string]interface{}, fallback string) string {
+	for _, key := range jsonSeverityKeys {
+		switch s := v[key].(type) {
+		case string:
+			return normalizeSeverity(s, fallback)
+		case float64:
+			if s >= 2 {
+				return "error"
+			}
+			return "warning"
+		}
+	}
+	return fallback
+}
+
+// sarifLog is the subset of SARIF 2.1.0 needed to extract findings
+type sarifLog struct {
+	Runs []struct {
+		Results []struct {
+			RuleID  string `json:"ruleId"`
+			Level   string `json:"level"`
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine   int `json:"startLine"`
+						StartColumn int `json:"startColumn"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+// parseSARIF extracts the results of every run of a SARIF log
+func parseSARIF(output []byte, dir, defaultSeverity string) ([]Diagnostic, error) {
+	var log sarifLog
+	if err := json.Unmarshal(output, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse SARIF output: %w", err)
+	}
+
+	diags := make([]Diagnostic, 0)
+	for _, run := range log.Runs {
+		for _, result := range run.Results {
+			d := Diagnostic{
+				Rule:     result.RuleID,
+				Message:  result.Message.Text,
+				Severity: normalizeSeverity(result.Level, defaultSeverity),
+			}
+			if len(result.Locations) > 0 {
+				location := result.Locations[0].PhysicalLocation
+				d.File = resolvePath(dir, strings.TrimPrefix(location.ArtifactLocation.URI, "file://"))
+				d.Line = location.Region.StartLine
+				d.Column = location.Region.StartColumn
+			}
+			diags = append(diags, d)
+		}
+	}
+	return diags, nil
+}
@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseGofmtDiff(t *testing.T) {
+	output := strings.Join([]string{
+		"diff -u a.go.orig a.go",
+		"--- a.go.orig",
+		"+++ a.go",
+		"@@ -3,7 +3,7 @@",
+		` import "fmt"`,
+		" ",
+		" func main() {",
+		`-fmt.Println("hi")`,
+		`+	fmt.Println("hi")`,
+		" }",
+		" ",
+		" func other() {",
+		"@@ -20,3 +20,3 @@",
+		"-x  := 1",
+		"+x := 1",
+		" y := 2",
+	}, "\n")
+
+	want := []Diagnostic{
+		{File: "/repo/a.go", Line: 6, Rule: "gofmt", Message: "file is not gofmt-formatted", Severity: "error"},
+		{File: "/repo/a.go", Line: 20, Rule: "gofmt", Message: "file is not gofmt-formatted", Severity: "error"},
+	}
+	if got := parseGofmtDiff(output, "/repo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseVetJSON(t *testing.T) {
+	output := `# example.com/a
+{
+	"example.com/a": {
+		"printf": [
+			{
+				"posn": "/repo/a.go:12:2",
+				"message": "fmt.Printf format %d has arg s of wrong type string"
+			}
+		]
+	}
+}
+# example.com/b
+vet: b/b.go:4:9: undefined: missing`
+
+	want := []Diagnostic{
+		{File: "/repo/a.go", Line: 12, Column: 2, Rule: "printf", Message: "fmt.Printf format %d has arg s of wrong type string", Severity: "error"},
+		{File: "/repo/b/b.go", Line: 4, Column: 9, Message: "undefined: missing", Severity: "error"},
+	}
+	if got := parseVetJSON(output, "/repo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGolangciJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []Diagnostic
+		wantErr bool
+	}{
+		{
+			name:   "issues",
+			output: `level=warning msg="x"` + "\n" + `{"Issues":[{"FromLinter":"errcheck","Text":"unchecked error","Severity":"","Pos":{"Filename":"a.go","Line":3,"Column":5}},{"FromLinter":"gosec","Text":"weak","Severity":"Warning","Pos":{"Filename":"b.go","Line":1,"Column":0}}]}`,
+			want: []Diagnostic{
+				{File: "/repo/a.go", Line: 3, Column: 5, Rule: "errcheck", Message: "unchecked error", Severity: "error"},
+				{File: "/repo/b.go", Line: 1, Rule: "gosec", Message: "weak", Severity: "warning"},
+			},
+		},
+		{name: "no issues", output: `{"Issues":[]}`, want: []Diagnostic{}},
+		{name: "no report", output: "level=error msg=\"typecheck failed\"", wantErr: true},
+		{name: "truncated report", output: `{"Issues":[`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGolangciJSON([]byte(tt.output), "/repo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGCCStyle(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []Diagnostic
+	}{
+		{"with column and severity", "src/a.c:10:4: warning: unused variable [-Wunused]",
+			[]Diagnostic{{File: "/repo/src/a.c", Line: 10, Column: 4, Message: "unused variable", Rule: "-Wunused", Severity: "warning"}}},
+		{"without column", "a.go:7: missing return", []Diagnostic{{File: "/repo/a.go", Line: 7, Message: "missing return", Severity: "error"}}},
+		{"fatal error", "/abs/x.h:1:1: fatal error: no such file", []Diagnostic{{File: "/abs/x.h", Line: 1, Column: 1, Message: "no such file", Severity: "error"}}},
+		{"note", "b.py:2:1: note: defined here", []Diagnostic{{File: "/repo/b.py", Line: 2, Column: 1, Message: "defined here", Severity: "info"}}},
+		{"not a diagnostic", "Found 3 errors.", []Diagnostic{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGCCStyle(tt.line, "/repo", "error"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	re := regexp.MustCompile(`^(?P<severity>\w+) (?P<file>\S+)#L(?P<line>\d+) (?P<rule>\w+): (?P<message>.*)$`)
+	output := "ERROR x.sql#L4 L010: keywords must be upper case\nsummary line\nwarn y.sql#L1 L003: indent "
+
+	want := []Diagnostic{
+		{File: "/repo/x.sql", Line: 4, Rule: "L010", Message: "keywords must be upper case", Severity: "error"},
+		{File: "/repo/y.sql", Line: 1, Rule: "L003", Message: "indent", Severity: "warning"},
+	}
+	if got := parseRegex(output, re, "/repo", "info"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseJSONDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []Diagnostic
+		wantErr bool
+	}{
+		{
+			name:   "eslint nested messages",
+			output: `[{"filePath":"/repo/a.js","messages":[{"ruleId":"no-undef","severity":2,"message":"x is not defined","line":3,"column":1},{"ruleId":"semi","severity":1,"message":"Missing semicolon","line":4}]}]`,
+			want: []Diagnostic{
+				{File: "/repo/a.js", Line: 3, Column: 1, Rule: "no-undef", Message: "x is not defined", Severity: "error"},
+				{File: "/repo/a.js", Line: 4, Rule: "semi", Message: "Missing semicolon", Severity: "warning"},
+			},
+		},
+		{
+			name:   "object stream",
+			output: "{\"path\":\"a.py\",\"line\":1,\"code\":\"E1\",\"message\":\"bad\",\"level\":\"warning\"}\n{\"path\":\"b.py\",\"line\":2,\"message\":\"worse\"}",
+			want: []Diagnostic{
+				{File: "/repo/a.py", Line: 1, Rule: "E1", Message: "bad", Severity: "warning"},
+				{File: "/repo/b.py", Line: 2, Message: "worse", Severity: "error"},
+			},
+		},
+		{name: "wrapped empty list", output: `{"issues":[]}`, want: []Diagnostic{}},
+		{name: "invalid", output: `[{"file":`, want: []Diagnostic{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONDiagnostics([]byte(tt.output), "/repo", "error")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSARIF(t *testing.T) {
+	output := `{"version":"2.1.0","runs":[{"results":[
+		{"ruleId":"G101","level":"warning","message":{"text":"hardcoded credentials"},
+		 "locations":[{"physicalLocation":{"artifactLocation":{"uri":"file:///repo/a.go"},"region":{"startLine":9,"startColumn":2}}}]},
+		{"ruleId":"R1","message":{"text":"no location"}}
+	]}]}`
+
+	want := []Diagnostic{
+		{File: "/repo/a.go", Line: 9, Column: 2, Rule: "G101", Message: "hardcoded credentials", Severity: "warning"},
+		{Rule: "R1", Message: "no location", Severity: "error"},
+	}
+	got, err := parseSARIF([]byte(output), "/repo", "error")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := parseSARIF([]byte("not json"), "/repo", "error"); err == nil {
+		t.Error("expected an error for invalid SARIF")
+	}
+}
+
+func TestRunGoFmt_SyntaxError(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt not installed")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "bad.go")
+	if err := os.WriteFile(file, []byte("package bad\n\nfunc {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := runGoFmt(context.Background(), dir, []string{file})
+	if result.Success {
+		t.Fatal("expected a file that doesn't parse to fail gofmt")
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].File != file || result.Diagnostics[0].Line != 3 {
+		t.Errorf("got diagnostics %+v, want one at %s:3", result.Diagnostics, file)
+	}
+}
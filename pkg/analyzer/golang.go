@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// goFormatChecker runs gofmt in the format stage
+type goFormatChecker struct{}
+
+func (goFormatChecker) Name() string { return "go-format" }
+
+func (goFormatChecker) Match(file string) bool { return hasExtension(file, ".go") }
+
+func (goFormatChecker) Available() bool { return commandExists("gofmt") }
+
+func (goFormatChecker) Stage() string { return config.StageFormat }
+
+func (goFormatChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	return []CheckResult{runGoFmt(ctx, repoPath, files)}
+}
+
+// goChecker runs go vet and, when installed, golangci-lint
+type goChecker struct{}
+
+func (goChecker) Name() string { return "go" }
+
+func (goChecker) Match(file string) bool { return hasExtension(file, ".go") }
+
+func (goChecker) Available() bool { return commandExists("go") }
+
+func (goChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	results := make([]CheckResult, 0)
+
+	// Run go vet
+	vetResult := runGoVet(ctx, repoPath)
+	results = append(results, vetResult)
+
+	// Run golangci-lint if available
+	if commandExists("golangci-lint") {
+		lintResult := runGolangciLint(ctx, repoPath)
+		results = append(results, lintResult)
+	}
+
+	return results
+}
+
+func runGoFmt(ctx context.Context, repoPath string, files []string) CheckResult {
+	// Diff each file against its formatted form to locate the issues
+	cmd := exec.CommandContext(ctx, "gofmt", "-d")
+	cmd.Args = append(cmd.Args, files...)
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	outputStr := strings.TrimSpace(string(output))
+	diags := parseGofmtDiff(outputStr, repoPath)
+
+	// Files gofmt can't parse produce no diff, only errors on stderr
+	if errText := strings.TrimSpace(stderr.String()); errText != "" {
+		diags = append(parseGCCStyle(errText, repoPath, "error"), diags...)
+		outputStr = strings.TrimSpace(errText + "\n" + outputStr)
+	}
+
+	if err != nil || len(diags) > 0 {
+		return failedResult("gofmt", "Go formatting issues found", outputStr, diags)
+	}
+
+	return CheckResult{
+		Tool:     "gofmt",
+		Severity: "info",
+		Message:  "All Go files properly formatted",
+		Success:  true,
+	}
+}
+
+func runGoVet(ctx context.Context, repoPath string) CheckResult {
+	cmd := exec.CommandContext(ctx, "go", "vet", "-json", "./...")
+	cmd.Dir = repoPath
+
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	diags := parseVetJSON(outputStr, repoPath)
+
+	// -json exits zero even when analyzers report findings
+	if err != nil || len(diags) > 0 {
+		return failedResult("go vet", "Go vet found issues", outputStr, diags)
+	}
+
+	return CheckResult{
+		Tool:     "go vet",
+		Severity: "info",
+		Message:  "No issues found by go vet",
+		Success:  true,
+	}
+}
+
+func runGolangciLint(ctx context.Context, repoPath string) CheckResult {
+	cmd := exec.CommandContext(ctx, "golangci-lint", golangciJSONArgs(ctx)...)
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	outputStr := strings.TrimSpace(string(output))
+	diags, parseErr := parseGolangciJSON(output, repoPath)
+
+	if err != nil || len(diags) > 0 {
+		if parseErr != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				outputStr = strings.TrimSpace(outputStr + "\n" + string(exitErr.Stderr))
+			}
+		}
+		return failedResult("golangci-lint", "Linter found issues", outputStr, diags)
+	}
+
+	return CheckResult{
+		Tool:     "golangci-lint",
+		Severity: "info",
+		Message:  "No linting issues found",
+		Success:  true,
+	}
+}
+
+// golangciJSONArgs builds the run arguments for JSON output, which changed
+// flags between golangci-lint v1 and v2
+func golangciJSONArgs(ctx context.Context) []string {
+	output, err := exec.CommandContext(ctx, "golangci-lint", "--version").Output()
+	if err == nil && golangciV2.Match(output) {
+		return []string{"run", "--output.json.path=stdout", "--show-stats=false"}
+	}
+	return []string{"run", "--out-format=json"}
+}
+
+var golangciV2 = regexp.MustCompile(`version v?2\.`)
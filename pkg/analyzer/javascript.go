@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// javaScriptChecker runs eslint on JavaScript and TypeScript files
+type javaScriptChecker struct{}
+
+func (javaScriptChecker) Name() string { return "eslint" }
+
+func (javaScriptChecker) Match(file string) bool {
+	return hasExtension(file, ".js", ".jsx", ".ts", ".tsx")
+}
+
+func (javaScriptChecker) Available() bool { return commandExists("eslint") }
+
+func (javaScriptChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	results := make([]CheckResult, 0)
+
+	cmd := exec.CommandContext(ctx, "eslint")
+	cmd.Args = append(cmd.Args, files...)
+	cmd.Dir = repoPath
+
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+
+	if err != nil {
+		results = append(results, CheckResult{
+			Tool:     "eslint",
+			Severity: "error",
+			Message:  "ESLint found issues",
+			Success:  false,
+			Output:   outputStr,
+			Errors:   []string{outputStr},
+		})
+	} else {
+		results = append(results, CheckResult{
+			Tool:     "eslint",
+			Severity: "info",
+			Message:  "No ESLint issues found",
+			Success:  true,
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// pythonChecker compiles Python files and runs ruff and mypy when installed,
+// once per project found through pyproject.toml
+type pythonChecker struct{}
+
+func (pythonChecker) Name() string { return "python" }
+
+func (pythonChecker) Match(file string) bool { return hasExtension(file, ".py", ".pyi") }
+
+func (pythonChecker) Available() bool { return pythonInterpreter() != "" }
+
+func (pythonChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	projects := pythonProjects(repoPath, files)
+	results := make([]CheckResult, 0)
+
+	results = append(results, runProjectTool(ctx, projects, projectTool{
+		name:    "py_compile",
+		failure: "Python syntax errors found",
+		success: "All Python files compile",
+		command: pythonCompileCommand,
+		parse:   parsePythonCompile,
+	}))
+
+	if commandExists("ruff") {
+		results = append(results, runProjectTool(ctx, projects, projectTool{
+			name:    "ruff",
+			failure: "Ruff found issues",
+			success: "No issues found by ruff",
+			command: func(files []string) []string {
+				return append([]string{"ruff", "check", "--output-format=json", "--force-exclude", "--no-fix"}, files...)
+			},
+			parse: parseRuffJSON,
+		}))
+	}
+
+	if commandExists("mypy") {
+		results = append(results, runProjectTool(ctx, projects, projectTool{
+			name:    "mypy",
+			failure: "Mypy found type errors",
+			success: "No type errors found by mypy",
+			command: func(files []string) []string {
+				return append([]string{"mypy", "--show-column-numbers", "--show-error-codes", "--no-error-summary", "--no-color-output"}, files...)
+			},
+			parse: func(stdout []byte, combined, dir string) []Diagnostic {
+				return parseGCCStyle(string(stdout), dir, "error")
+			},
+		}))
+	}
+
+	return results
+}
+
+// pythonFormatChecker runs ruff format --check in the format stage
+type pythonFormatChecker struct{}
+
+func (pythonFormatChecker) Name() string { return "python-format" }
+
+func (pythonFormatChecker) Match(file string) bool { return hasExtension(file, ".py", ".pyi") }
+
+func (pythonFormatChecker) Available() bool { return commandExists("ruff") }
+
+func (pythonFormatChecker) Stage() string { return config.StageFormat }
+
+func (pythonFormatChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	return []CheckResult{runProjectTool(ctx, pythonProjects(repoPath, files), projectTool{
+		name:    "ruff format",
+		failure: "Python formatting issues found",
+		success: "All Python files properly formatted",
+		command: func(files []string) []string {
+			return append([]string{"ruff", "format", "--check", "--force-exclude"}, files...)
+		},
+		parse: parseRuffFormat,
+	})}
+}
+
+// pythonProjects groups files by the directory of their nearest
+// pyproject.toml, falling back to the repository root
+func pythonProjects(repoPath string, files []string) map[string][]string {
+	root := absPath(repoPath)
+	projects := make(map[string][]string)
+	for _, file := range files {
+		// Changed files are relative when the repository path is
+		file = absPath(file)
+		dir := root
+		for d := filepath.Dir(file); strings.HasPrefix(d, root); d = filepath.Dir(d) {
+			if fileExists(filepath.Join(d, "pyproject.toml")) {
+				dir = d
+				break
+			}
+			if d == root || d == filepath.Dir(d) {
+				break
+			}
+		}
+		projects[dir] = append(projects[dir], file)
+	}
+	return projects
+}
+
+func pythonInterpreter() string {
+	for _, name := range []string{"python3", "python"} {
+		if commandExists(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// pythonCompileScript compiles each file in memory, so no .pyc files are
+// written, and prints syntax errors in gcc style
+const pythonCompileScript = `import sys
+for path in sys.argv[1:]:
+    try:
+        with open(path, "rb") as f:
+            compile(f.read(), path, "exec")
+    except SyntaxError as e:
+        print("%s:%d:%d: error: %s [%s]" % (path, e.lineno or 0, e.offset or 0, e.msg, type(e).__name__))
+`
+
+func pythonCompileCommand(files []string) []string {
+	return append([]string{pythonInterpreter(), "-c", pythonCompileScript}, files...)
+}
+
+func parsePythonCompile(stdout []byte, combined, dir string) []Diagnostic {
+	return parseGCCStyle(string(stdout), dir, "error")
+}
+
+// ruffIssue is one entry of `ruff check --output-format=json`
+type ruffIssue struct {
+	Code     *string `json:"code"`
+	Message  string  `json:"message"`
+	Filename string  `json:"filename"`
+	Location struct {
+		Row    int `json:"row"`
+		Column int `json:"column"`
+	} `json:"location"`
+}
+
+func parseRuffJSON(stdout []byte, combined, dir string) []Diagnostic {
+	var issues []ruffIssue
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), &issues); err != nil {
+		return nil
+	}
+
+	diags := make([]Diagnostic, 0, len(issues))
+	for _, issue := range issues {
+		rule := "syntax"
+		if issue.Code != nil {
+			rule = *issue.Code
+		}
+		diags = append(diags, Diagnostic{
+			File:     resolvePath(dir, issue.Filename),
+			Line:     issue.Location.Row,
+			Column:   issue.Location.Column,
+			Rule:     rule,
+			Message:  issue.Message,
+			Severity: "error",
+		})
+	}
+	return diags
+}
+
+// parseRuffFormat reports each "Would reformat: <file>" line of ruff format --check
+func parseRuffFormat(stdout []byte, combined, dir string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, line := range strings.Split(combined, "\n") {
+		file, ok := strings.CutPrefix(strings.TrimSpace(line), "Would reformat: ")
+		if !ok {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			File:     resolvePath(dir, file),
+			Rule:     "ruff-format",
+			Message:  fmt.Sprintf("file is not formatted, run 'ruff format %s'", file),
+			Severity: "error",
+		})
+	}
+	return diags
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPythonProjects(t *testing.T) {
+	dir, rel := relativeRepo(t)
+	writeFiles(t, dir, map[string]string{
+		"py/pyproject.toml": "[project]\nname = \"py\"\n",
+		"py/pkg/m.py":       "",
+		"tool.py":           "",
+	})
+
+	tests := []struct {
+		name     string
+		repoPath string
+	}{
+		{"absolute repo path", dir},
+		{"relative repo path", rel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []string{filepath.Join(tt.repoPath, "py/pkg/m.py"), filepath.Join(tt.repoPath, "tool.py")}
+			want := map[string][]string{
+				filepath.Join(dir, "py"): {filepath.Join(dir, "py/pkg/m.py")},
+				dir:                      {filepath.Join(dir, "tool.py")},
+			}
+			if got := pythonProjects(tt.repoPath, files); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// rustFormatChecker runs cargo fmt --check in the format stage, once per
+// Cargo workspace owning the changed files
+type rustFormatChecker struct{}
+
+func (rustFormatChecker) Name() string { return "rust-format" }
+
+func (rustFormatChecker) Match(file string) bool { return hasExtension(file, ".rs") }
+
+func (rustFormatChecker) Available() bool { return commandExists("cargo") && commandExists("rustfmt") }
+
+func (rustFormatChecker) Stage() string { return config.StageFormat }
+
+func (rustFormatChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	workspaces := cargoWorkspaces(ctx, repoPath, files)
+	if len(workspaces) == 0 {
+		return []CheckResult{}
+	}
+	return []CheckResult{runProjectTool(ctx, workspaces, projectTool{
+		name:    "cargo fmt",
+		failure: "Rust formatting issues found",
+		success: "All Rust files properly formatted",
+		command: func([]string) []string { return []string{"cargo", "fmt", "--all", "--check"} },
+		parse:   parseRustfmtCheck,
+	})}
+}
+
+// rustChecker runs cargo clippy, or cargo check when clippy is not
+// installed, once per Cargo workspace owning the changed files
+type rustChecker struct{}
+
+func (rustChecker) Name() string { return "rust" }
+
+func (rustChecker) Match(file string) bool { return hasExtension(file, ".rs") }
+
+func (rustChecker) Available() bool { return commandExists("cargo") }
+
+func (rustChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	workspaces := cargoWorkspaces(ctx, repoPath, files)
+	results := make([]CheckResult, 0)
+	if len(workspaces) == 0 {
+		return results
+	}
+
+	if commandExists("cargo-clippy") {
+		results = append(results, runProjectTool(ctx, workspaces, projectTool{
+			name:    "cargo clippy",
+			failure: "Clippy found issues",
+			success: "No issues found by clippy",
+			command: func([]string) []string {
+				return []string{"cargo", "clippy", "--all-targets", "--message-format=json", "--quiet", "--", "-D", "warnings"}
+			},
+			parse: parseCargoJSON,
+		}))
+	} else {
+		results = append(results, runProjectTool(ctx, workspaces, projectTool{
+			name:    "cargo check",
+			failure: "Rust compilation errors found",
+			success: "Rust code compiles",
+			command: func([]string) []string {
+				return []string{"cargo", "check", "--all-targets", "--message-format=json", "--quiet"}
+			},
+			parse: parseCargoJSON,
+		}))
+	}
+
+	return results
+}
+
+// cargoWorkspaces groups files by the root of the Cargo workspace that owns
+// them; files outside any crate are skipped
+func cargoWorkspaces(ctx context.Context, repoPath string, files []string) map[string][]string {
+	root := absPath(repoPath)
+	workspaces := make(map[string][]string)
+	byCrate := make(map[string]string)
+	for _, file := range files {
+		// Changed files are relative when the repository path is
+		file = absPath(file)
+		crate := nearestCargoManifest(root, filepath.Dir(file))
+		if crate == "" {
+			continue
+		}
+
+		workspace, ok := byCrate[crate]
+		if !ok {
+			workspace = locateWorkspace(ctx, crate)
+			byCrate[crate] = workspace
+		}
+		workspaces[workspace] = append(workspaces[workspace], file)
+	}
+	return workspaces
+}
+
+// nearestCargoManifest walks up from dir to root looking for Cargo.toml
+func nearestCargoManifest(root, dir string) string {
+	for d := dir; strings.HasPrefix(d, root); d = filepath.Dir(d) {
+		if fileExists(filepath.Join(d, "Cargo.toml")) {
+			return d
+		}
+		if d == root || d == filepath.Dir(d) {
+			break
+		}
+	}
+	return ""
+}
+
+// locateWorkspace asks cargo for the workspace root of a crate, falling back
+// to the crate itself
+func locateWorkspace(ctx context.Context, crate string) string {
+	cmd := exec.CommandContext(ctx, "cargo", "locate-project", "--workspace", "--message-format", "plain")
+	cmd.Dir = crate
+	output, err := cmd.Output()
+	if err != nil {
+		return crate
+	}
+	return filepath.Dir(strings.TrimSpace(string(output)))
+}
+
+var (
+	rustfmtDiff = regexp.MustCompile(`^Diff in (.+?)(?: at line |:)(\d+):?$`)
+	ansiEscape  = regexp.MustCompile(`\x1b(?:\[[0-9;]*m|\(B)`)
+)
+
+// parseRustfmtCheck reports each hunk of `cargo fmt --check`, located at
+// the first line rustfmt would change
+func parseRustfmtCheck(stdout []byte, combined, dir string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	var pending *Diagnostic
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(string(stdout), ""), "\n") {
+		if m := rustfmtDiff.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			pending = &Diagnostic{
+				File:     resolvePath(dir, m[1]),
+				Line:     lineNo,
+				Rule:     "rustfmt",
+				Message:  "file is not rustfmt-formatted",
+				Severity: "error",
+			}
+			continue
+		}
+		if pending == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+") {
+			diags = append(diags, *pending)
+			pending = nil
+		} else {
+			pending.Line++
+		}
+	}
+	return diags
+}
+
+// cargoMessage is a compiler-message line of cargo's JSON output
+type cargoMessage struct {
+	Reason  string `json:"reason"`
+	Message struct {
+		Message string `json:"message"`
+		Level   string `json:"level"`
+		Code    *struct {
+			Code string `json:"code"`
+		} `json:"code"`
+		Spans []struct {
+			FileName    string `json:"file_name"`
+			LineStart   int    `json:"line_start"`
+			ColumnStart int    `json:"column_start"`
+			IsPrimary   bool   `json:"is_primary"`
+		} `json:"spans"`
+	} `json:"message"`
+}
+
+// parseCargoJSON maps compiler messages onto their primary span; the same
+// message reported for several targets is kept once
+func parseCargoJSON(stdout []byte, combined, dir string) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg cargoMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Reason != "compiler-message" {
+			continue
+		}
+
+		for _, span := range msg.Message.Spans {
+			if !span.IsPrimary {
+				continue
+			}
+			d := Diagnostic{
+				File:     resolvePath(dir, span.FileName),
+				Line:     span.LineStart,
+				Column:   span.ColumnStart,
+				Message:  msg.Message.Message,
+				Severity: normalizeSeverity(msg.Message.Level, "error"),
+			}
+			if msg.Message.Code != nil {
+				d.Rule = msg.Message.Code.Code
+			}
+
+			if key := d.String(); !seen[key] {
+				seen[key] = true
+				diags = append(diags, d)
+			}
+			break
+		}
+	}
+	return diags
+}
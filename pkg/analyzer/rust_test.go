@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// relativeRepo creates a repository directory and returns it both as an
+// absolute path and relative to the working directory, like repo_path: "."
+func relativeRepo(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, rel
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCargoWorkspaces(t *testing.T) {
+	dir, rel := relativeRepo(t)
+	writeFiles(t, dir, map[string]string{
+		"module/Cargo.toml": "[package]\nname = \"module\"\nversion = \"0.1.0\"\n",
+		"module/src/lib.rs": "",
+		"loose.rs":          "",
+	})
+
+	tests := []struct {
+		name     string
+		repoPath string
+	}{
+		{"absolute repo path", dir},
+		{"relative repo path", rel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []string{filepath.Join(tt.repoPath, "module/src/lib.rs"), filepath.Join(tt.repoPath, "loose.rs")}
+			want := map[string][]string{filepath.Join(dir, "module"): {filepath.Join(dir, "module/src/lib.rs")}}
+			if got := cargoWorkspaces(context.Background(), tt.repoPath, files); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
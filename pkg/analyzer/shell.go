@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// shellChecker runs shellcheck on each shell script
+type shellChecker struct{}
+
+func (shellChecker) Name() string { return "shellcheck" }
+
+func (shellChecker) Match(file string) bool { return hasExtension(file, ".sh", ".bash") }
+
+func (shellChecker) Available() bool { return commandExists("shellcheck") }
+
+func (shellChecker) Run(ctx context.Context, repoPath string, files []string) []CheckResult {
+	results := make([]CheckResult, 0)
+
+	for _, file := range files {
+		cmd := exec.CommandContext(ctx, "shellcheck", "-f", "gcc", file)
+		output, err := cmd.CombinedOutput()
+		outputStr := strings.TrimSpace(string(output))
+
+		if err != nil {
+			result := failedResult("shellcheck", "Shellcheck found issues", outputStr, parseGCCStyle(outputStr, repoPath, "error"))
+			result.File = file
+			results = append(results, result)
+		} else {
+			results = append(results, CheckResult{
+				Tool:     "shellcheck",
+				File:     file,
+				Severity: "info",
+				Message:  "No shell script issues",
+				Success:  true,
+			})
+		}
+	}
+
+	return results
+}
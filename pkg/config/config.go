@@ -1,25 +1,138 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the configuration file
 type Config struct {
-	Tests []TestConfig `yaml:"tests"`
+	Tests        []TestConfig  `yaml:"tests"`
+	Parallel     int           `yaml:"parallel"` // tests run at once (default 1)
+	Checks       []CheckConfig `yaml:"checks"`
+	CommitPolicy *CommitPolicy `yaml:"commit_policy"`
+	Range        *RangeConfig  `yaml:"range"`
+	Pipeline     *Pipeline     `yaml:"pipeline"`
 }
 
 // TestConfig represents a test configuration
 type TestConfig struct {
-	Name     string `yaml:"name"`
-	Command  string `yaml:"command"`
-	Blocking bool   `yaml:"blocking"`
-	Timeout  int    `yaml:"timeout"` // in seconds
+	Name       string            `yaml:"name"`
+	Command    string            `yaml:"command"`
+	Args       []string          `yaml:"args"`        // argv mode: program and arguments, used instead of command
+	Mode       string            `yaml:"mode"`        // argv (default) or shell
+	Env        map[string]string `yaml:"env"`         // variables added to the environment
+	ClearEnv   bool              `yaml:"clear_env"`   // start from an empty environment instead of the guardian's
+	PassEnv    []string          `yaml:"pass_env"`    // variables kept when clear_env is set, e.g. PATH and HOME
+	WorkingDir string            `yaml:"working_dir"` // relative to the repository root
+	DependsOn  []string          `yaml:"depends_on"`  // tests that must finish first; a blocking failure skips this one
+	Stage      string            `yaml:"stage"`       // pipeline stage (default: test)
+	Blocking   bool              `yaml:"blocking"`
+	Timeout    int               `yaml:"timeout"` // in seconds
 }
 
+// Test command modes
+const (
+	ModeArgv  = "argv"
+	ModeShell = "shell"
+)
+
+// CheckConfig represents a custom static check
+type CheckConfig struct {
+	Name      string   `yaml:"name"`
+	Command   string   `yaml:"command"`
+	Mode      string   `yaml:"mode"`       // argv (default) or shell
+	Patterns  []string `yaml:"patterns"`   // globs the check applies to, e.g. "*.proto" or "db/**/*.sql"
+	PassFiles bool     `yaml:"pass_files"` // append the matched files to the command
+	Parser    string   `yaml:"parser"`     // gcc (default), json, sarif, regex or none
+	Pattern   string   `yaml:"pattern"`    // regex parser: named groups file, line, column, severity, rule, message
+	Severity  string   `yaml:"severity"`   // severity of findings that don't report one (default: error)
+	Blocking  bool     `yaml:"blocking"`
+	Timeout   int      `yaml:"timeout"` // in seconds
+	Stage     string   `yaml:"stage"`   // pipeline stage (default: lint)
+}
+
+// CommitPolicy configures the rules commit messages are checked against
+type CommitPolicy struct {
+	Types            []string `yaml:"types"`              // allowed conventional-commit types; empty disables the format check
+	RequireScope     bool     `yaml:"require_scope"`      // require "type(scope): subject"
+	MaxSubjectLength int      `yaml:"max_subject_length"` // 0 disables the length check
+	Imperative       bool     `yaml:"imperative"`         // flag subjects starting with "Added", "Fixes", ...
+	TicketPattern    string   `yaml:"ticket_pattern"`     // regex that must match the subject, body or trailers
+	ForbidWIP        bool     `yaml:"forbid_wip"`         // reject WIP, fixup!, squash! and amend! commits
+	Blocking         bool     `yaml:"blocking"`
+}
+
+// Pipeline stages, in their default order
+const (
+	StageFormat = "format"
+	StageLint   = "lint"
+	StageBuild  = "build"
+	StageTest   = "test"
+)
+
+// Pipeline orders validate_push into stages; after a stage with a blocking
+// failure the later stages are skipped unless RunAll is set
+type Pipeline struct {
+	Stages []string `yaml:"stages"`  // default: format, lint, build, test
+	RunAll bool     `yaml:"run_all"` // run every stage regardless of failures, e.g. in CI
+}
+
+// DefaultPipeline is used when the config has no pipeline section
+func DefaultPipeline() *Pipeline {
+	return &Pipeline{Stages: []string{StageFormat, StageLint, StageBuild, StageTest}}
+}
+
+// StageOrder returns the pipeline's stages followed by any default stage it
+// leaves out, so built-in checks always run
+func (p *Pipeline) StageOrder() []string {
+	stages := append([]string{}, p.Stages...)
+	for _, stage := range DefaultPipeline().Stages {
+		found := false
+		for _, s := range p.Stages {
+			found = found || s == stage
+		}
+		if !found {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
+
+// RangeConfig configures how unpushed commits are found for a branch that
+// has no counterpart on the remote yet
+type RangeConfig struct {
+	Fallback      string `yaml:"fallback"`       // remotes (default) or merge-base
+	DefaultBranch string `yaml:"default_branch"` // merge-base target (default: the remote's HEAD)
+}
+
+// DefaultCommitPolicy follows the commit format in CODE_STANDARDS.md and is
+// advisory; it applies when the config has no commit_policy section
+func DefaultCommitPolicy() *CommitPolicy {
+	return &CommitPolicy{
+		Types:            []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+		MaxSubjectLength: 72,
+		Imperative:       true,
+		ForbidWIP:        true,
+	}
+}
+
+// Check output parsers
+const (
+	ParserGCC   = "gcc"
+	ParserJSON  = "json"
+	ParserSARIF = "sarif"
+	ParserRegex = "regex"
+	ParserNone  = "none"
+)
+
 // Load loads the configuration from a file
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -32,33 +145,329 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Set defaults
-	for i := range config.Tests {
-		if config.Tests[i].Timeout == 0 {
-			config.Tests[i].Timeout = 300 // 5 minutes default
-		}
+	config.setDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &config, nil
+}
+
+// ParseStrict parses configuration data, rejecting unknown fields
+func ParseStrict(data []byte) (*Config, error) {
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return nil, err
 	}
 
+	config.setDefaults()
 	return &config, nil
 }
 
+func (c *Config) setDefaults() {
+	if c.Parallel == 0 {
+		c.Parallel = 1
+	}
+	if c.Pipeline != nil && len(c.Pipeline.Stages) == 0 {
+		c.Pipeline.Stages = DefaultPipeline().Stages
+	}
+	for i := range c.Tests {
+		if c.Tests[i].Timeout == 0 {
+			c.Tests[i].Timeout = 300 // 5 minutes default
+		}
+		if c.Tests[i].Stage == "" {
+			c.Tests[i].Stage = StageTest
+		}
+		if c.Tests[i].Mode == "" {
+			c.Tests[i].Mode = ModeArgv
+		}
+	}
+	for i := range c.Checks {
+		if c.Checks[i].Timeout == 0 {
+			c.Checks[i].Timeout = 300
+		}
+		if c.Checks[i].Parser == "" {
+			c.Checks[i].Parser = ParserGCC
+		}
+		if c.Checks[i].Severity == "" {
+			c.Checks[i].Severity = "error"
+		}
+		if c.Checks[i].Stage == "" {
+			c.Checks[i].Stage = StageLint
+		}
+	}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if len(c.Tests) == 0 {
-		return fmt.Errorf("no tests configured")
+	if len(c.Tests) == 0 && len(c.Checks) == 0 && c.CommitPolicy == nil && c.Range == nil && c.Pipeline == nil {
+		return fmt.Errorf("no tests, checks, commit policy, range or pipeline configured")
+	}
+
+	for _, test := range c.Tests {
+		if err := test.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Parallel < 0 {
+		return fmt.Errorf("parallel must be positive")
+	}
+	if err := c.validateStages(); err != nil {
+		return err
+	}
+	if err := c.validateDependencies(); err != nil {
+		return err
+	}
+
+	for _, check := range c.Checks {
+		if err := check.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CommitPolicy != nil {
+		if c.CommitPolicy.MaxSubjectLength < 0 {
+			return fmt.Errorf("commit policy max_subject_length must be positive")
+		}
+		if _, err := regexp.Compile(c.CommitPolicy.TicketPattern); err != nil {
+			return fmt.Errorf("invalid commit policy ticket_pattern: %w", err)
+		}
+	}
+
+	if c.Range != nil {
+		switch c.Range.Fallback {
+		case "", "remotes", "merge-base":
+		default:
+			return fmt.Errorf("unknown range fallback '%s' (want remotes or merge-base)", c.Range.Fallback)
+		}
+	}
+
+	return nil
+}
+
+// PipelineOrDefault returns the configured pipeline or the default one
+func (c *Config) PipelineOrDefault() *Pipeline {
+	if c != nil && c.Pipeline != nil {
+		return c.Pipeline
+	}
+	return DefaultPipeline()
+}
+
+// validateStages checks that stages are unique and that every check and
+// test names one of them
+func (c *Config) validateStages() error {
+	order := make(map[string]int)
+	for i, stage := range c.PipelineOrDefault().StageOrder() {
+		if stage == "" {
+			return fmt.Errorf("pipeline stage name cannot be empty")
+		}
+		if _, ok := order[stage]; ok {
+			return fmt.Errorf("duplicate pipeline stage '%s'", stage)
+		}
+		order[stage] = i
 	}
 
+	for _, check := range c.Checks {
+		if _, ok := order[check.Stage]; check.Stage != "" && !ok {
+			return fmt.Errorf("check '%s' uses unknown stage '%s'", check.Name, check.Stage)
+		}
+	}
+	stages := make(map[string]string, len(c.Tests))
 	for _, test := range c.Tests {
-		if test.Name == "" {
-			return fmt.Errorf("test name cannot be empty")
+		if _, ok := order[test.Stage]; test.Stage != "" && !ok {
+			return fmt.Errorf("test '%s' uses unknown stage '%s'", test.Name, test.Stage)
 		}
-		if test.Command == "" {
-			return fmt.Errorf("test command cannot be empty for test '%s'", test.Name)
+		stages[test.Name] = test.Stage
+	}
+	for _, test := range c.Tests {
+		for _, dep := range test.DependsOn {
+			if depStage, ok := stages[dep]; ok && order[depStage] > order[test.Stage] {
+				return fmt.Errorf("test '%s' in stage '%s' depends on '%s' in later stage '%s'", test.Name, test.Stage, dep, depStage)
+			}
 		}
-		if test.Timeout < 0 {
-			return fmt.Errorf("test timeout must be positive for test '%s'", test.Name)
+	}
+	return nil
+}
+
+// validateDependencies checks that test names are unique and that
+// depends_on names existing tests without forming a cycle
+func (c *Config) validateDependencies() error {
+	index := make(map[string]int, len(c.Tests))
+	for i, test := range c.Tests {
+		if _, ok := index[test.Name]; ok {
+			return fmt.Errorf("duplicate test name '%s'", test.Name)
+		}
+		index[test.Name] = i
+	}
+	for _, test := range c.Tests {
+		for _, dep := range test.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("test '%s' depends on unknown test '%s'", test.Name, dep)
+			}
 		}
 	}
 
+	return c.findCycle(index)
+}
+
+// findCycle reports a depends_on cycle with a depth-first search; a test
+// reached again while on the path closes it
+func (c *Config) findCycle(index map[string]int) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(c.Tests))
+	path := make([]string, 0)
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			start := 0
+			for path[start] != c.Tests[i].Name {
+				start++
+			}
+			return fmt.Errorf("test dependency cycle: %s -> %s", strings.Join(path[start:], " -> "), c.Tests[i].Name)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		path = append(path, c.Tests[i].Name)
+		for _, dep := range c.Tests[i].DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range c.Tests {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// Validate checks if a test is valid and its command can be parsed
+func (t *TestConfig) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("test name cannot be empty")
+	}
+	if t.Command == "" && len(t.Args) == 0 {
+		return fmt.Errorf("test command cannot be empty for test '%s'", t.Name)
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("test timeout must be positive for test '%s'", t.Name)
+	}
+
+	switch t.Mode {
+	case "", ModeArgv:
+		if t.Command != "" && len(t.Args) > 0 {
+			return fmt.Errorf("test '%s' sets both command and args", t.Name)
+		}
+		if _, _, err := t.Argv(); err != nil {
+			return err
+		}
+	case ModeShell:
+		if len(t.Args) > 0 {
+			return fmt.Errorf("test '%s' uses shell mode, which runs command, not args", t.Name)
+		}
+	default:
+		return fmt.Errorf("unknown mode '%s' for test '%s' (want argv or shell)", t.Mode, t.Name)
+	}
+
+	for name := range t.Env {
+		if !isEnvName(name) {
+			return fmt.Errorf("invalid environment variable name '%s' for test '%s'", name, t.Name)
+		}
+	}
+
+	if t.WorkingDir != "" {
+		dir := filepath.Clean(t.WorkingDir)
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("working_dir for test '%s' must be inside the repository", t.Name)
+		}
+	}
+
+	return nil
+}
+
+// Argv returns the program and arguments of an argv-mode test, and the
+// NAME=value assignments that precede the program in command
+func (t *TestConfig) Argv() ([]string, []string, error) {
+	if len(t.Args) > 0 {
+		return t.Args, nil, nil
+	}
+
+	words, err := SplitCommand(t.Command)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse command for test '%s': %w (use mode: shell for shell syntax)", t.Name, err)
+	}
+	assignments, argv := splitAssignments(words)
+	if len(argv) == 0 {
+		return nil, nil, fmt.Errorf("test command cannot be empty for test '%s'", t.Name)
+	}
+	return argv, assignments, nil
+}
+
+// Validate checks if a custom check is valid
+func (c *CheckConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("check name cannot be empty")
+	}
+	if c.Command == "" {
+		return fmt.Errorf("check command cannot be empty for check '%s'", c.Name)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("check timeout must be positive for check '%s'", c.Name)
+	}
+
+	switch c.Mode {
+	case "", ModeArgv:
+		if _, _, err := c.Argv(); err != nil {
+			return err
+		}
+	case ModeShell:
+	default:
+		return fmt.Errorf("unknown mode '%s' for check '%s' (want argv or shell)", c.Mode, c.Name)
+	}
+
+	switch c.Parser {
+	case "", ParserGCC, ParserJSON, ParserSARIF, ParserNone:
+	case ParserRegex:
+		if c.Pattern == "" {
+			return fmt.Errorf("check '%s' uses the regex parser but has no pattern", c.Name)
+		}
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern for check '%s': %w", c.Name, err)
+		}
+	default:
+		return fmt.Errorf("unknown parser '%s' for check '%s'", c.Parser, c.Name)
+	}
+
+	switch c.Severity {
+	case "", "error", "warning", "info":
+	default:
+		return fmt.Errorf("unknown severity '%s' for check '%s'", c.Severity, c.Name)
+	}
+
+	return nil
+}
+
+// Argv returns the program and arguments of an argv-mode check, and the
+// NAME=value assignments that precede the program in command
+func (c *CheckConfig) Argv() ([]string, []string, error) {
+	words, err := SplitCommand(c.Command)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse command for check '%s': %w (use mode: shell for shell syntax)", c.Name, err)
+	}
+	assignments, argv := splitAssignments(words)
+	if len(argv) == 0 {
+		return nil, nil, fmt.Errorf("check command cannot be empty for check '%s'", c.Name)
+	}
+	return argv, assignments, nil
+}
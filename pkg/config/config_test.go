@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   CheckConfig
+		wantErr string
+	}{
+		{"argv", CheckConfig{Name: "c", Command: `grep -n "TODO: fix" --`}, ""},
+		{"shell syntax in shell mode", CheckConfig{Name: "c", Command: "grep x | wc -l", Mode: ModeShell}, ""},
+		{"shell syntax in argv mode", CheckConfig{Name: "c", Command: "grep x | wc -l"}, "use mode: shell"},
+		{"unterminated quote", CheckConfig{Name: "c", Command: `grep "x`}, "cannot parse command for check 'c'"},
+		{"only assignments", CheckConfig{Name: "c", Command: "A=1"}, "check command cannot be empty"},
+		{"unknown mode", CheckConfig{Name: "c", Command: "true", Mode: "bash"}, "unknown mode 'bash'"},
+		{"regex without pattern", CheckConfig{Name: "c", Command: "true", Parser: ParserRegex}, "has no pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		test    TestConfig
+		wantErr string
+	}{
+		{"argv", TestConfig{Name: "t", Command: "go test ./..."}, ""},
+		{"shell", TestConfig{Name: "t", Command: "npm ci && npm test", Mode: ModeShell}, ""},
+		{"shell syntax in argv mode", TestConfig{Name: "t", Command: "npm ci && npm test"}, "use mode: shell"},
+		{"command and args", TestConfig{Name: "t", Command: "go test", Args: []string{"go"}}, "sets both command and args"},
+		{"args in shell mode", TestConfig{Name: "t", Args: []string{"go"}, Mode: ModeShell}, "runs command, not args"},
+		{"unknown mode", TestConfig{Name: "t", Command: "true", Mode: "zsh"}, "unknown mode 'zsh'"},
+		{"invalid env name", TestConfig{Name: "t", Command: "true", Env: map[string]string{"A-B": "1"}}, "invalid environment variable name 'A-B'"},
+		{"working dir outside", TestConfig{Name: "t", Command: "true", WorkingDir: "../x"}, "must be inside the repository"},
+		{"absolute working dir", TestConfig{Name: "t", Command: "true", WorkingDir: "/tmp"}, "must be inside the repository"},
+		{"working dir inside", TestConfig{Name: "t", Command: "true", WorkingDir: "web/../api"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.test.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	test := func(name string, deps ...string) TestConfig {
+		return TestConfig{Name: name, Command: "true", DependsOn: deps}
+	}
+	tests := []struct {
+		name    string
+		tests   []TestConfig
+		wantErr string
+	}{
+		{"no dependencies", []TestConfig{test("a"), test("b")}, ""},
+		{"diamond", []TestConfig{test("d", "b", "c"), test("b", "a"), test("c", "a"), test("a")}, ""},
+		{"duplicate name", []TestConfig{test("a"), test("a")}, "duplicate test name 'a'"},
+		{"unknown dependency", []TestConfig{test("a", "missing")}, "test 'a' depends on unknown test 'missing'"},
+		{"self dependency", []TestConfig{test("a", "a")}, "test dependency cycle: a -> a"},
+		{"cycle", []TestConfig{test("a", "b"), test("b", "c"), test("c", "a")}, "test dependency cycle: a -> b -> c -> a"},
+		{"cycle behind a root", []TestConfig{test("root", "x"), test("x", "y"), test("y", "x")}, "test dependency cycle: x -> y -> x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Tests: tt.tests}
+			err := cfg.validateDependencies()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
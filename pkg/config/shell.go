@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shellOperators are unquoted characters that need a shell to mean anything
+const shellOperators = "|&;<>()`$"
+
+// SplitCommand splits a command into arguments following POSIX shell quoting:
+// single quotes are literal, double quotes allow \" \\ \$ and \` escapes and
+// a backslash outside quotes escapes the next character. Pipes, redirections,
+// substitutions and other syntax that needs a shell are rejected
+func SplitCommand(command string) ([]string, error) {
+	var word strings.Builder
+	args, inWord := make([]string, 0), false
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 == len(command) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			if command[i] != '\n' {
+				word.WriteByte(command[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			end, err := doubleQuoted(command, i+1, &word)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			inWord = true
+		case strings.IndexByte(shellOperators, c) >= 0:
+			return nil, fmt.Errorf("shell syntax %q needs a shell", c)
+		case c == '#' && !inWord:
+			return nil, fmt.Errorf("comment needs a shell")
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// doubleQuoted writes the double-quoted text starting at command[start] to
+// word and returns the index of the closing quote
+func doubleQuoted(command string, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(command) && strings.IndexByte("\"\\$`\n", command[i+1]) >= 0:
+			i++
+			if command[i] != '\n' {
+				word.WriteByte(command[i])
+			}
+		case c == '$' || c == '`':
+			return 0, fmt.Errorf("%q inside double quotes needs a shell", c)
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// splitAssignments separates leading NAME=value words, which a shell would
+// apply as environment variables, from the program and its arguments
+func splitAssignments(args []string) ([]string, []string) {
+	for i, arg := range args {
+		name, _, ok := strings.Cut(arg, "=")
+		if !ok || !isEnvName(name) {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
+
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr string
+	}{
+		{"words", "go test  -race\t./...", []string{"go", "test", "-race", "./..."}, ""},
+		{"empty", "   ", []string{}, ""},
+		{"single quotes", `go test -run 'TestA|TestB' ./...`, []string{"go", "test", "-run", "TestA|TestB", "./..."}, ""},
+		{"double quotes", `echo "a b" "c\"d" "e\\f" "\$HOME"`, []string{"echo", "a b", `c"d`, `e\f`, "$HOME"}, ""},
+		{"kept backslash in double quotes", `echo "a\nb"`, []string{"echo", `a\nb`}, ""},
+		{"adjacent quotes join", `echo a'b c'"d"`, []string{"echo", "ab cd"}, ""},
+		{"empty quoted argument", `printf '' ""`, []string{"printf", "", ""}, ""},
+		{"escaped space", `ls my\ dir`, []string{"ls", "my dir"}, ""},
+		{"line continuation", "go test \\\n ./...", []string{"go", "test", "./..."}, ""},
+		{"hash inside word", "echo a#b", []string{"echo", "a#b"}, ""},
+		{"assignments", "CGO_ENABLED=0 go build", []string{"CGO_ENABLED=0", "go", "build"}, ""},
+		{"pipe", "go test | tee out", nil, `shell syntax '|' needs a shell`},
+		{"and", "npm ci && npm test", nil, `shell syntax '&' needs a shell`},
+		{"redirect", "go test > out", nil, `shell syntax '>' needs a shell`},
+		{"variable", "echo $HOME", nil, `shell syntax '$' needs a shell`},
+		{"substitution in double quotes", `echo "$(date)"`, nil, `'$' inside double quotes needs a shell`},
+		{"comment", "go test # all", nil, "comment needs a shell"},
+		{"unterminated single quote", "echo 'a", nil, "unterminated single quote"},
+		{"unterminated double quote", `echo "a`, nil, "unterminated double quote"},
+		{"trailing backslash", `echo a\`, nil, "trailing backslash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitCommand(tt.command)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAssignments(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		wantAssignments []string
+		wantArgv        []string
+	}{
+		{"none", []string{"go", "test"}, []string{}, []string{"go", "test"}},
+		{"leading", []string{"A=1", "_B2=x=y", "go", "C=3"}, []string{"A=1", "_B2=x=y"}, []string{"go", "C=3"}},
+		{"invalid name", []string{"1A=1", "go"}, []string{}, []string{"1A=1", "go"}},
+		{"only assignments", []string{"A=1"}, []string{"A=1"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignments, argv := splitAssignments(tt.args)
+			if !reflect.DeepEqual(assignments, tt.wantAssignments) || !reflect.DeepEqual(argv, tt.wantArgv) {
+				t.Errorf("got %q, %q, want %q, %q", assignments, argv, tt.wantAssignments, tt.wantArgv)
+			}
+		})
+	}
+}
+
+func TestTestConfigArgv(t *testing.T) {
+	tests := []struct {
+		name     string
+		test     TestConfig
+		wantArgv []string
+		wantEnv  []string
+		wantErr  bool
+	}{
+		{"command", TestConfig{Name: "t", Command: `GOFLAGS=-count=1 go test -run 'A|B'`}, []string{"go", "test", "-run", "A|B"}, []string{"GOFLAGS=-count=1"}, false},
+		{"args", TestConfig{Name: "t", Args: []string{"go", "test", "a b"}}, []string{"go", "test", "a b"}, nil, false},
+		{"shell syntax", TestConfig{Name: "t", Command: "go test && echo ok"}, nil, nil, true},
+		{"only assignments", TestConfig{Name: "t", Command: "A=1"}, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv, env, err := tt.test.Argv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (!reflect.DeepEqual(argv, tt.wantArgv) || !reflect.DeepEqual(env, tt.wantEnv)) {
+				t.Errorf("got %q, %q, want %q, %q", argv, env, tt.wantArgv, tt.wantEnv)
+			}
+		})
+	}
+}
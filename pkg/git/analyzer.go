@@ -4,29 +4,68 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 // Commit represents a Git commit
 type Commit struct {
-	Hash    string   `json:"hash"`
-	Author  string   `json:"author"`
-	Date    string   `json:"date"`
-	Message string   `json:"message"`
-	Files   []string `json:"files"`
-	Diff    string   `json:"diff,omitempty"`
+	Hash      string    `json:"hash"`
+	Parents   []string  `json:"parents,omitempty"`
+	Author    string    `json:"author"`
+	Date      string    `json:"date"`
+	Message   string    `json:"message"` // subject line
+	Body      string    `json:"body,omitempty"`
+	Trailers  []Trailer `json:"trailers,omitempty"`
+	Files     []string  `json:"files"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	Diff      string    `json:"diff,omitempty"`
 }
 
+// Trailer is a "Key: value" line at the end of a commit message
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// logFormat starts each commit with \x1e and separates fields with \x1f.
+// Used with -z --numstat, every commit header and numstat entry is
+// NUL-terminated, so subjects, bodies and paths may contain any other text
+const logFormat = "--format=%x1e%H%x1f%P%x1f%an%x1f%ai%x1f%s%x1f%b%x1f%(trailers:only,unfold)"
+
+// diffFormat prefixes each patch of git log -p with NUL and the commit hash;
+// NUL never appears in a text diff
+const diffFormat = "--format=%x00%H"
+
+// Strategies for finding the unpushed commits of a branch with no remote counterpart
+const (
+	FallbackRemotes   = "remotes"    // commits not on any remote-tracking branch
+	FallbackMergeBase = "merge-base" // commits since the merge-base with the default branch
+)
+
 // Analyzer handles Git repository analysis
 type Analyzer struct {
-	repoPath string
+	repoPath      string
+	fallback      string
+	defaultBranch string
 }
 
 // NewAnalyzer creates a new Git analyzer
 func NewAnalyzer(repoPath string) *Analyzer {
 	return &Analyzer{
 		repoPath: repoPath,
+		fallback: FallbackRemotes,
+	}
+}
+
+// SetFallback sets the strategy used for branches with no remote counterpart;
+// defaultBranch is the merge-base target and defaults to the remote's HEAD
+func (a *Analyzer) SetFallback(strategy, defaultBranch string) {
+	if strategy != "" {
+		a.fallback = strategy
 	}
+	a.defaultBranch = defaultBranch
 }
 
 // GetUnpushedCommits retrieves commits that haven't been pushed to remote
@@ -45,120 +84,212 @@ func (a *Analyzer) GetUnpushedCommits(remote, branch string) ([]Commit, error) {
 	remoteBranch := fmt.Sprintf("%s/%s", remote, branch)
 	cmd := exec.Command("git", "-C", a.repoPath, "rev-parse", "--verify", remoteBranch)
 	if err := cmd.Run(); err != nil {
-		// Remote branch doesn't exist, return all commits
-		return a.getAllCommits()
+		// Remote branch doesn't exist, use the fallback range
+		return a.newBranchCommits(remote, "HEAD", "--remotes")
 	}
 
 	// Get unpushed commits
-	cmd = exec.Command("git", "-C", a.repoPath, "log", fmt.Sprintf("%s..HEAD", remoteBranch), "--format=%H|||%an|||%ai|||%s")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	commits := make([]Commit, 0, len(lines))
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+	return a.logCommits(fmt.Sprintf("%s..HEAD", remoteBranch))
+}
 
-		parts := strings.Split(line, "|||")
-		if len(parts) < 4 {
-			continue
-		}
+// GetPushCommits returns the commits a push of localSHA sends to remote.
+// For a new branch (zero remoteSHA), or when remoteSHA isn't known locally,
+// that is every commit not already on one of the remote's branches, or on
+// any remote-tracking branch when remote is a URL.
+func (a *Analyzer) GetPushCommits(remote, localSHA, remoteSHA string) ([]Commit, error) {
+	if !IsZeroSHA(remoteSHA) && a.hasCommit(remoteSHA) {
+		return a.logCommits(fmt.Sprintf("%s..%s", remoteSHA, localSHA))
+	}
+	remotes := "--remotes"
+	if a.isRemote(remote) {
+		remotes = "--remotes=" + remote
+	}
+	return a.newBranchCommits(remote, localSHA, remotes)
+}
 
-		commit := Commit{
-			Hash:    parts[0],
-			Author:  parts[1],
-			Date:    parts[2],
-			Message: parts[3],
+// newBranchCommits lists the commits of tip that remote doesn't have yet,
+// excluding those reachable from the refs selected by remotes, or since the
+// merge-base with the default branch when that strategy is set
+func (a *Analyzer) newBranchCommits(remote, tip, remotes string) ([]Commit, error) {
+	if a.fallback == FallbackMergeBase {
+		if base, ok := a.mergeBase(remote, tip); ok {
+			return a.logCommits(fmt.Sprintf("%s..%s", base, tip))
 		}
+	}
+	return a.logCommits(tip, "--not", remotes)
+}
 
-		// Get files changed in this commit
-		files, err := a.getCommitFiles(commit.Hash)
+// mergeBase finds where tip forked from the default branch
+func (a *Analyzer) mergeBase(remote, tip string) (string, bool) {
+	for _, ref := range a.defaultBranchRefs(remote) {
+		output, err := exec.Command("git", "-C", a.repoPath, "merge-base", ref, tip).Output()
 		if err == nil {
-			commit.Files = files
+			return strings.TrimSpace(string(output)), true
 		}
+	}
+	return "", false
+}
 
-		// Get diff for this commit
-		diff, err := a.getCommitDiff(commit.Hash)
-		if err == nil {
-			commit.Diff = diff
-		}
+// defaultBranchRefs lists candidate refs for the default branch, most specific first
+func (a *Analyzer) defaultBranchRefs(remote string) []string {
+	if a.defaultBranch != "" {
+		return []string{fmt.Sprintf("%s/%s", remote, a.defaultBranch), a.defaultBranch}
+	}
 
-		commits = append(commits, commit)
+	refs := make([]string, 0, 3)
+	output, err := exec.Command("git", "-C", a.repoPath, "symbolic-ref", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote)).Output()
+	if err == nil {
+		refs = append(refs, strings.TrimSpace(string(output)))
 	}
+	return append(refs, remote+"/main", remote+"/master")
+}
 
-	return commits, nil
+// logCommits lists the commits selected by revision arguments with their
+// files, in one git process; LoadDiffs adds the patches for callers that need them
+func (a *Analyzer) logCommits(revs ...string) ([]Commit, error) {
+	args := append([]string{"-C", a.repoPath, "log", "-z", "--numstat", logFormat}, revs...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
+	}
+	return parseLog(string(output)), nil
 }
 
-// getAllCommits gets all commits (used when remote doesn't exist)
-func (a *Analyzer) getAllCommits() ([]Commit, error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "log", "--format=%H|||%an|||%ai|||%s")
+// LoadDiffs fills in the Diff of every commit that has none, reading all
+// the patches with one git process
+func (a *Analyzer) LoadDiffs(commits []Commit) error {
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		if commit.Diff == "" {
+			hashes = append(hashes, commit.Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("git", "-C", a.repoPath, "log", "--no-walk=unsorted", "--stdin", "-p", "--no-color", "--no-ext-diff", diffFormat)
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all commits: %w", err)
+		return fmt.Errorf("failed to get commit diffs: %w", err)
+	}
+	diffs := parseDiffs(string(output))
+	for i := range commits {
+		if commits[i].Diff == "" {
+			commits[i].Diff = diffs[commits[i].Hash]
+		}
 	}
+	return nil
+}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	commits := make([]Commit, 0, len(lines))
+func (a *Analyzer) hasCommit(sha string) bool {
+	return exec.Command("git", "-C", a.repoPath, "cat-file", "-e", sha+"^{commit}").Run() == nil
+}
 
-	for _, line := range lines {
-		if line == "" {
+// IsZeroSHA reports whether sha is git's all-zero object name
+func IsZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// parseLog parses the NUL-delimited output of git log -z --numstat with logFormat
+func parseLog(output string) []Commit {
+	commits := make([]Commit, 0)
+	tokens := strings.Split(output, "\x00")
+
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimPrefix(tokens[i], "\n")
+		if header, ok := strings.CutPrefix(token, "\x1e"); ok {
+			commits = append(commits, parseHeader(header))
 			continue
 		}
-
-		parts := strings.Split(line, "|||")
-		if len(parts) < 4 {
+		if len(commits) == 0 || token == "" {
 			continue
 		}
 
-		commit := Commit{
-			Hash:    parts[0],
-			Author:  parts[1],
-			Date:    parts[2],
-			Message: parts[3],
+		// numstat entry "added\tdeleted\tpath"; renames and copies leave the
+		// path empty and follow it with the old and new paths as two tokens
+		fields := strings.SplitN(token, "\t", 3)
+		if len(fields) != 3 {
+			continue
 		}
-
-		files, err := a.getCommitFiles(commit.Hash)
-		if err == nil {
-			commit.Files = files
+		path := fields[2]
+		if path == "" && i+2 < len(tokens) {
+			path = tokens[i+2]
+			i += 2
 		}
 
-		commits = append(commits, commit)
+		commit := &commits[len(commits)-1]
+		commit.Files = append(commit.Files, path)
+		// Binary files report "-" and count as no lines
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		commit.Additions += added
+		commit.Deletions += deleted
 	}
-
-	return commits, nil
+	return commits
 }
 
-// getCommitFiles returns the list of files changed in a commit
-func (a *Analyzer) getCommitFiles(hash string) ([]string, error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "diff-tree", "--no-commit-id", "--name-only", "-r", hash)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// parseHeader parses the fields of logFormat; the body is recovered from
+// around the other fields so a stray \x1f in it can't shift them
+func parseHeader(header string) Commit {
+	parts := strings.SplitN(header, "\x1f", 6)
+	for len(parts) < 6 {
+		parts = append(parts, "")
+	}
+	body, trailerText := parts[5], ""
+	if i := strings.LastIndex(parts[5], "\x1f"); i >= 0 {
+		body, trailerText = parts[5][:i], parts[5][i+1:]
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	files := make([]string, 0, len(lines))
-	for _, line := range lines {
-		if line != "" {
-			files = append(files, line)
+	trailers := parseTrailers(trailerText)
+	return Commit{
+		Hash:     parts[0],
+		Parents:  strings.Fields(parts[1]),
+		Author:   parts[2],
+		Date:     parts[3],
+		Message:  parts[4],
+		Body:     stripTrailers(strings.TrimSpace(body), len(trailers) > 0),
+		Trailers: trailers,
+		Files:    []string{},
+	}
+}
+
+// parseDiffs splits git log -p output produced with diffFormat by commit
+func parseDiffs(output string) map[string]string {
+	diffs := make(map[string]string)
+	for _, record := range strings.Split(output, "\x00") {
+		hash, diff, ok := strings.Cut(record, "\n")
+		if !ok {
+			continue
 		}
+		diffs[hash] = strings.TrimPrefix(diff, "\n")
 	}
-	return files, nil
+	return diffs
 }
 
-// getCommitDiff returns the diff for a commit
-func (a *Analyzer) getCommitDiff(hash string) (string, error) {
-	cmd := exec.Command("git", "-C", a.repoPath, "show", hash, "--format=", "--no-color")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+// stripTrailers removes the trailer paragraph git includes at the end of %b
+func stripTrailers(body string, hasTrailers bool) string {
+	if !hasTrailers {
+		return body
 	}
-	return string(output), nil
+	if i := strings.LastIndex(body, "\n\n"); i >= 0 {
+		return strings.TrimSpace(body[:i])
+	}
+	return ""
+}
+
+// parseTrailers parses the output of %(trailers:only,unfold)
+func parseTrailers(text string) []Trailer {
+	trailers := make([]Trailer, 0)
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		trailers = append(trailers, Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
 }
 
 // GetChangedFiles extracts all unique files from a list of commits
@@ -197,3 +328,192 @@ func (a *Analyzer) GetStagedFiles() ([]string, error) {
 	}
 	return files, nil
 }
+
+// GetStagedDiff returns the diff of the changes staged for commit
+func (a *Analyzer) GetStagedDiff() (string, error) {
+	cmd := exec.Command("git", "-C", a.repoPath, "diff", "--cached", "--no-color")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	return string(output), nil
+}
+
+// emptyTree is the hash of git's empty tree, used as the base of root commits
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// LineRange is an inclusive range of line numbers
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ChangedLines maps absolute file paths to their added or modified lines
+type ChangedLines map[string][]LineRange
+
+// Contains reports whether a line of a file was changed; line 0 matches any
+// change to the file
+func (c ChangedLines) Contains(file string, line int) bool {
+	ranges, ok := c[file]
+	if !ok {
+		return false
+	}
+	if line <= 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChangedLines returns the lines added or modified by a list of commits
+// such as those of GetUnpushedCommits or GetPushCommits. The range is
+// diffed from its boundary: the parents outside the list, which are what the
+// remote already has (the empty tree for root commits). When a merge brings
+// in several boundary parents, a line only counts as changed when it differs
+// from all of them, so merged-in upstream work isn't attributed to the push
+func (a *Analyzer) GetChangedLines(commits []Commit) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	tips, bases := rangeEnds(commits)
+
+	for _, tip := range tips {
+		var tipLines ChangedLines
+		for _, base := range bases {
+			lines, err := a.diffLines(base, tip)
+			if err != nil {
+				return nil, err
+			}
+			tipLines = tipLines.intersect(lines)
+		}
+		for file, ranges := range tipLines {
+			changed[file] = append(changed[file], ranges...)
+		}
+	}
+	return changed, nil
+}
+
+// rangeEnds returns the commits of a list that no other commit in it has as
+// parent, and the parents outside the list, or the empty tree when there are none
+func rangeEnds(commits []Commit) ([]string, []string) {
+	inRange := make(map[string]bool, len(commits))
+	isParent := make(map[string]bool)
+	for _, commit := range commits {
+		inRange[commit.Hash] = true
+		for _, parent := range commit.Parents {
+			isParent[parent] = true
+		}
+	}
+
+	tips, bases := make([]string, 0), make([]string, 0)
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if !isParent[commit.Hash] {
+			tips = append(tips, commit.Hash)
+		}
+		for _, parent := range commit.Parents {
+			if !inRange[parent] && !seen[parent] {
+				seen[parent] = true
+				bases = append(bases, parent)
+			}
+		}
+	}
+	if len(bases) == 0 {
+		bases = append(bases, emptyTree)
+	}
+	return tips, bases
+}
+
+// diffLines returns the lines of tip added or modified since base
+func (a *Analyzer) diffLines(base, tip string) (ChangedLines, error) {
+	cmd := exec.Command("git", "-C", a.repoPath, "-c", "core.quotePath=false",
+		"diff", "-U0", "--no-color", "--no-ext-diff", "--no-prefix", base, tip)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed lines: %w", err)
+	}
+
+	root, err := filepath.Abs(a.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository path: %w", err)
+	}
+	return parseChangedLines(string(output), root), nil
+}
+
+// parseChangedLines collects the new-side hunk ranges of a -U0 --no-prefix
+// diff by absolute path under root
+func parseChangedLines(diff, root string) ChangedLines {
+	changed := make(ChangedLines)
+	file := ""
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = ""
+			if path := DiffPath(strings.TrimPrefix(line, "+++ ")); path != "/dev/null" {
+				file = filepath.Join(root, path)
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			if r, ok := parseHunkRange(line); ok {
+				changed[file] = append(changed[file], r)
+			}
+		}
+	}
+	return changed
+}
+
+// DiffPath decodes a file name of a diff header: git appends a tab to names
+// containing spaces and C-quotes names with special characters
+func DiffPath(name string) string {
+	name = strings.TrimSuffix(name, "\t")
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return name
+}
+
+// intersect returns the lines changed in both c and other; a nil c stands
+// for every line, so intersecting from nil starts with other
+func (c ChangedLines) intersect(other ChangedLines) ChangedLines {
+	if c == nil {
+		return other
+	}
+	result := make(ChangedLines)
+	for file, ranges := range c {
+		for _, r := range ranges {
+			for _, o := range other[file] {
+				start, end := max(r.Start, o.Start), min(r.End, o.End)
+				if start <= end {
+					result[file] = append(result[file], LineRange{Start: start, End: end})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// parseHunkRange extracts the new-side range from a hunk header such as
+// "@@ -3,2 +4,5 @@"; pure deletions have no new lines and report false
+func parseHunkRange(header string) (LineRange, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, false
+	}
+
+	start, count := fields[2][1:], "1"
+	if i := strings.IndexByte(start, ','); i >= 0 {
+		start, count = start[:i], start[i+1:]
+	}
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return LineRange{}, false
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n == 0 {
+		return LineRange{}, false
+	}
+	return LineRange{Start: first, End: first + n - 1}, true
+}
@@ -0,0 +1,293 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRepo is a throwaway repository for tests that need real git history
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	r.git("config", "user.name", "Test")
+	r.git("config", "user.email", "test@example.com")
+	r.git("config", "commit.gpgsign", "false")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", r.dir}, args...)...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func (r *testRepo) write(name, content string) {
+	r.t.Helper()
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *testRepo) commit(message string) string {
+	r.t.Helper()
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", message)
+	return r.git("rev-parse", "HEAD")
+}
+
+func TestParseChangedLines(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a.go a.go",
+		"--- a.go",
+		"+++ a.go",
+		"@@ -3,0 +4,2 @@ func main() {",
+		"@@ -10 +12 @@",
+		"@@ -20,3 +21,0 @@",
+		"diff --git dir with space/b.go dir with space/b.go",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ dir with space/b.go\t",
+		"@@ -0,0 +1,3 @@",
+		`+++ "t\303\251st\tname.go"`,
+		"@@ -1 +1 @@",
+		"diff --git gone.go gone.go",
+		"--- gone.go",
+		"+++ /dev/null",
+		"@@ -1,4 +0,0 @@",
+	}, "\n")
+
+	want := ChangedLines{
+		"/repo/a.go":                {{Start: 4, End: 5}, {Start: 12, End: 12}},
+		"/repo/dir with space/b.go": {{Start: 1, End: 3}},
+		"/repo/tést\tname.go":       {{Start: 1, End: 1}},
+	}
+	if got := parseChangedLines(diff, "/repo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestChangedLinesIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b ChangedLines
+		want ChangedLines
+	}{
+		{"nil starts with other", nil, ChangedLines{"f": {{1, 3}}}, ChangedLines{"f": {{1, 3}}}},
+		{"overlap", ChangedLines{"f": {{1, 5}, {10, 12}}}, ChangedLines{"f": {{4, 11}}}, ChangedLines{"f": {{4, 5}, {10, 11}}}},
+		{"disjoint", ChangedLines{"f": {{1, 2}}}, ChangedLines{"f": {{3, 4}}}, ChangedLines{}},
+		{"other file", ChangedLines{"f": {{1, 2}}}, ChangedLines{"g": {{1, 2}}}, ChangedLines{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.intersect(tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetChangedLines(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("dir with space/a.go", "one\ntwo\nthree\n")
+	root := r.commit("Add a")
+
+	// A root commit has no parent to diff against
+	a := NewAnalyzer(r.dir)
+	commits, err := a.logCommits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spaced := filepath.Join(r.dir, "dir with space/a.go")
+	assertChanged(t, a, commits, spaced, []int{1, 2, 3}, nil)
+
+	// A branch merging in upstream work only owns its own lines
+	r.git("checkout", "-q", "-b", "feature")
+	r.write("dir with space/a.go", "one\nTWO\nthree\n")
+	r.commit("Change two")
+	r.git("checkout", "-q", "main")
+	r.write("dir with space/a.go", "one\ntwo\nthree\nfour\n")
+	r.write("upstream.go", "up\n")
+	upstream := r.commit("Add four")
+	r.git("checkout", "-q", "feature")
+	r.git("merge", "-q", "--no-edit", "main")
+	r.write("feature.go", "f\n")
+	tip := r.commit("Add feature")
+
+	commits, err = a.logCommits(upstream + ".." + tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("got %d commits, want 3", len(commits))
+	}
+	assertChanged(t, a, commits, spaced, []int{2}, []int{1, 3, 4})
+	assertChanged(t, a, commits, filepath.Join(r.dir, "feature.go"), []int{1}, nil)
+	assertChanged(t, a, commits, filepath.Join(r.dir, "upstream.go"), nil, []int{1})
+}
+
+func assertChanged(t *testing.T, a *Analyzer, commits []Commit, file string, changed, unchanged []int) {
+	t.Helper()
+	lines, err := a.GetChangedLines(commits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range changed {
+		if !lines.Contains(file, line) {
+			t.Errorf("expected %s:%d to be changed, got %v", file, line, lines)
+		}
+	}
+	for _, line := range unchanged {
+		if lines.Contains(file, line) {
+			t.Errorf("expected %s:%d to be unchanged, got %v", file, line, lines)
+		}
+	}
+}
+
+func TestGetPushCommits(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.go", "a\n")
+	pushed := r.commit("Add a")
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	r.git("init", "-q", "--bare", remote)
+	r.git("remote", "add", "origin", remote)
+	r.git("push", "-q", "origin", "main")
+	r.write("b.go", "b\n")
+	tip := r.commit("Add b")
+
+	a := NewAnalyzer(r.dir)
+	tests := []struct {
+		name      string
+		remote    string
+		remoteSHA string
+	}{
+		{"existing branch", "origin", pushed},
+		{"new branch", "origin", strings.Repeat("0", 40)},
+		{"new branch pushed by URL", remote, strings.Repeat("0", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, err := a.GetPushCommits(tt.remote, tip, tt.remoteSHA)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(commits) != 1 || commits[0].Hash != tip {
+				t.Errorf("got %+v, want only %s", commits, tip)
+			}
+		})
+	}
+}
+
+func TestAddWorktree(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.go", "one\n")
+	first := r.commit("Add a")
+	r.write("a.go", "two\n")
+	second := r.commit("Change a")
+
+	a := NewAnalyzer(r.dir)
+	if !a.IsCheckedOut(second) || a.IsCheckedOut(first) {
+		t.Fatal("expected only HEAD to be checked out")
+	}
+	r.write("a.go", "dirty\n")
+	if a.IsCheckedOut(second) {
+		t.Error("expected a modified tree not to count as checked out")
+	}
+
+	dir, remove, err := a.AddWorktree(first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	if err != nil || string(content) != "one\n" {
+		t.Errorf("got %q, %v, want the file at %s", content, err, first)
+	}
+
+	remove()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", dir, err)
+	}
+	if list := r.git("worktree", "list"); strings.Contains(list, dir) {
+		t.Errorf("expected the worktree to be pruned, got %s", list)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	header := func(hash, parents, subject, body, trailers string) string {
+		return "\x1e" + strings.Join([]string{hash, parents, "Ann", "2024-01-02 03:04:05 +0000", subject, body, trailers}, "\x1f")
+	}
+	output := strings.Join([]string{
+		header("bbb", "aaa", "Rename and edit", "Why it changed.\n\nRefs: ABC-1\n", "Refs: ABC-1\n"),
+		"\n3\t1\tmain.go",
+		"-\t-\tlogo.png",
+		"0\t0\t",
+		"old name.go",
+		"new\tname.go",
+		header("aaa", "", "Add main", "", "") + "\n",
+		"\n5\t0\tmain.go",
+		"",
+	}, "\x00")
+
+	want := []Commit{
+		{
+			Hash: "bbb", Parents: []string{"aaa"}, Author: "Ann", Date: "2024-01-02 03:04:05 +0000",
+			Message: "Rename and edit", Body: "Why it changed.", Trailers: []Trailer{{Key: "Refs", Value: "ABC-1"}},
+			Files: []string{"main.go", "logo.png", "new\tname.go"}, Additions: 3, Deletions: 1,
+		},
+		{
+			Hash: "aaa", Parents: []string{}, Author: "Ann", Date: "2024-01-02 03:04:05 +0000", Message: "Add main",
+			Trailers: []Trailer{}, Files: []string{"main.go"}, Additions: 5,
+		},
+	}
+	if got := parseLog(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadDiffs(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.go", "one\n")
+	first := r.commit("Add a")
+	r.write("a.go", "two\n")
+	second := r.commit("Change a")
+
+	a := NewAnalyzer(r.dir)
+	commits, err := a.logCommits(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Diff != "" {
+		t.Fatalf("expected two commits without diffs, got %+v", commits)
+	}
+	if err := a.LoadDiffs(commits); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{first: "+one", second: "-one\n+two"}
+	for _, commit := range commits {
+		if !strings.Contains(commit.Diff, want[commit.Hash]) {
+			t.Errorf("got diff %q for %.8s, want one containing %q", commit.Diff, commit.Hash, want[commit.Hash])
+		}
+	}
+}
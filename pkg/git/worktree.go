@@ -0,0 +1,56 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// IsCheckedOut reports whether the working tree is sha with no local changes
+// or untracked files, so validating it in place validates exactly sha
+func (a *Analyzer) IsCheckedOut(sha string) bool {
+	head, err := exec.Command("git", "-C", a.repoPath, "rev-parse", "HEAD").Output()
+	if err != nil || strings.TrimSpace(string(head)) != sha {
+		return false
+	}
+	status, err := exec.Command("git", "-C", a.repoPath, "status", "--porcelain").Output()
+	return err == nil && len(status) == 0
+}
+
+// AddWorktree checks sha out in a temporary worktree and returns its path
+// with a function that removes it
+func (a *Analyzer) AddWorktree(sha string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "git-guardian-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	output, err := exec.Command("git", "-C", a.repoPath, "-c", "core.hooksPath=/dev/null",
+		"worktree", "add", "--quiet", "--detach", dir, sha).CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to check out %s: %w: %s", sha, err, strings.TrimSpace(string(output)))
+	}
+
+	remove := func() {
+		_ = exec.Command("git", "-C", a.repoPath, "worktree", "remove", "--force", dir).Run()
+		os.RemoveAll(dir)
+	}
+	return dir, remove, nil
+}
+
+// isRemote reports whether name is a configured remote; git passes a URL
+// instead when pushing to one directly
+func (a *Analyzer) isRemote(name string) bool {
+	output, err := exec.Command("git", "-C", a.repoPath, "remote").Output()
+	if err != nil {
+		return false
+	}
+	for _, remote := range strings.Fields(string(output)) {
+		if remote == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrElicitationUnsupported is returned by Elicit when the client can't be asked
+var ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+// ElicitResult is the client's answer to an elicitation request
+type ElicitResult struct {
+	Action  string                 `json:"action"` // accept, decline or cancel
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+type callKey struct{}
+
+// callInfo lets a tool handler reach back to the client that called it
+type callInfo struct {
+	session   *session
+	send      func(msg interface{})
+	canStream bool
+}
+
+func withCall(ctx context.Context, info *callInfo) context.Context {
+	return context.WithValue(ctx, callKey{}, info)
+}
+
+// LocalClient reports whether a tool call came over stdio from the process
+// that started the server rather than from a network client
+func LocalClient(ctx context.Context) bool {
+	info, ok := ctx.Value(callKey{}).(*callInfo)
+	return ok && info.session.local
+}
+
+// Elicit asks the user, through the client, for input matching a flat
+// object schema (protocol 2025-06-18+)
+func Elicit(ctx context.Context, message string, schema *Schema) (*ElicitResult, error) {
+	info, ok := ctx.Value(callKey{}).(*callInfo)
+	if !ok || !info.canStream || !info.session.supportsElicitation() {
+		return nil, ErrElicitationUnsupported
+	}
+
+	resp, err := info.session.request(ctx, info.send, "elicitation/create", map[string]interface{}{
+		"message":         message,
+		"requestedSchema": schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result ElicitResult
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("invalid elicitation result: %w", err)
+	}
+	return &result, nil
+}
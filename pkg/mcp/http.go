@@ -0,0 +1,344 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionHeader  = "Mcp-Session-Id"
+	maxRequestSize = 4 << 20
+	keepAlive      = 30 * time.Second
+	// defaultSessionIdle is how long an HTTP session without requests or an
+	// open stream is kept before it expires
+	defaultSessionIdle = 30 * time.Minute
+)
+
+type noStreamKey struct{}
+
+// HTTPConfig configures the streamable HTTP transport
+type HTTPConfig struct {
+	Addr  string // bind address, e.g. 127.0.0.1:7391
+	Token string // bearer token required on every request, mandatory off loopback
+	// AllowedHosts are extra host names accepted in the Host and Origin
+	// headers besides loopback ones
+	AllowedHosts []string
+	// SessionIdle expires sessions idle for longer, 30 minutes if zero
+	SessionIdle time.Duration
+}
+
+// httpTransport implements the MCP streamable HTTP transport on a single endpoint
+type httpTransport struct {
+	server       *Server
+	token        string
+	allowedHosts map[string]bool
+	sessionIdle  time.Duration
+}
+
+// sseWriter writes JSON-RPC messages as server-sent events
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// ListenAndServe serves MCP over streamable HTTP on cfg.Addr at /mcp; it
+// refuses to bind a non-loopback address without a token
+func (s *Server) ListenAndServe(cfg HTTPConfig) error {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid bind address %q: %w", cfg.Addr, err)
+	}
+	if !isLoopback(host) && cfg.Token == "" {
+		return fmt.Errorf("refusing to listen on %s without a bearer token (set -token or GIT_GUARDIAN_TOKEN)", cfg.Addr)
+	}
+	if ip := net.ParseIP(host); host != "" && !isLoopback(host) && (ip == nil || !ip.IsUnspecified()) {
+		// Clients reach a specific bind address by its own name
+		cfg.AllowedHosts = append(cfg.AllowedHosts, host)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s.HTTPHandler(cfg))
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.logger.Printf("MCP HTTP server listening on %s", cfg.Addr)
+	if err := srv.ListenAndServe(); err != nil {
+		return fmt.Errorf("http server error: %w", err)
+	}
+	return nil
+}
+
+// HTTPHandler returns the streamable HTTP endpoint; an empty token disables auth
+func (s *Server) HTTPHandler(cfg HTTPConfig) http.Handler {
+	allowed := make(map[string]bool, len(cfg.AllowedHosts))
+	for _, host := range cfg.AllowedHosts {
+		allowed[strings.ToLower(host)] = true
+	}
+	idle := cfg.SessionIdle
+	if idle <= 0 {
+		idle = defaultSessionIdle
+	}
+	return &httpTransport{server: s, token: cfg.Token, allowedHosts: allowed, sessionIdle: idle}
+}
+
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !t.allowedHost(r) {
+		http.Error(w, "host not allowed", http.StatusForbidden)
+		return
+	}
+	if !t.allowedOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if !t.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="git-guardian-mcp"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleStream(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var probe struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(nil, -32700, fmt.Sprintf("Parse error: %v", err)))
+		return
+	}
+
+	sess := t.session(r)
+	if probe.Method == "initialize" {
+		t.server.expireSessions(t.sessionIdle)
+		sess = newSession(newSessionID())
+		t.server.addSession(sess)
+		w.Header().Set(sessionHeader, sess.id)
+	} else if r.Header.Get(sessionHeader) == "" {
+		http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+		return
+	} else if sess == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	sess.touch()
+	defer sess.touch()
+
+	// Notifications and client responses get no reply
+	if probe.ID == nil || probe.Method == "" {
+		t.server.handleMessage(r.Context(), sess, body, func(interface{}) {})
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	ctx, done := sess.track(r.Context(), probe.ID)
+	defer done()
+
+	// Tool calls stream progress notifications ahead of the final response
+	if probe.Method == "tools/call" && acceptsEventStream(r) {
+		sse := newSSEWriter(w)
+		if sse == nil {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		t.server.handleMessage(ctx, sess, body, sse.send)
+		return
+	}
+
+	// Plain JSON replies can't carry server-initiated requests
+	ctx = context.WithValue(ctx, noStreamKey{}, true)
+	var resp interface{}
+	t.server.handleMessage(ctx, sess, body, func(msg interface{}) {
+		if _, ok := msg.(Response); ok {
+			resp = msg
+		}
+	})
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleStream opens the session's stream for server-initiated notifications
+func (t *httpTransport) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "text/event-stream required", http.StatusNotAcceptable)
+		return
+	}
+	sess := t.session(r)
+	if sess == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	sse := newSSEWriter(w)
+	if sse == nil {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	sess.setNotify(sse.send)
+	defer sess.touch()
+	defer sess.setNotify(nil)
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			sse.comment("keep-alive")
+		}
+	}
+}
+
+func (t *httpTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sess := t.session(r)
+	if sess == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	t.server.removeSession(sess)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// session returns the session named by the request header, expiring it
+// first if it has been idle too long
+func (t *httpTransport) session(r *http.Request) *session {
+	sess := t.server.lookupSession(r.Header.Get(sessionHeader))
+	if sess != nil && sess.idle(t.sessionIdle) {
+		t.server.removeSession(sess)
+		return nil
+	}
+	return sess
+}
+
+func (t *httpTransport) authorized(r *http.Request) bool {
+	if t.token == "" {
+		return true
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	given := strings.TrimPrefix(header, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(t.token)) == 1
+}
+
+// allowedHost rejects requests addressed to a foreign host name, which is what
+// a DNS rebinding attack sends after pointing its own domain at us
+func (t *httpTransport) allowedHost(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	return t.trusted(host)
+}
+
+// allowedOrigin rejects browser requests from origins other than loopback or
+// the configured hosts
+func (t *httpTransport) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return t.trusted(u.Hostname())
+}
+
+// trusted reports whether host is loopback or explicitly allowed
+func (t *httpTransport) trusted(host string) bool {
+	host = strings.Trim(host, "[]")
+	return isLoopback(host) || t.allowedHosts[strings.ToLower(host)]
+}
+
+// isLoopback reports whether host names the local machine only
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// noStream reports whether the reply channel of a request is a single JSON body
+func noStream(ctx context.Context) bool {
+	v, _ := ctx.Value(noStreamKey{}).(bool)
+	return v
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+func (s *sseWriter) send(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, "event: message\ndata: %s\n\n", data)
+	s.flusher.Flush()
+}
+
+func (s *sseWriter) comment(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, ": %s\n\n", text)
+	s.flusher.Flush()
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPHostAndOriginChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		origin string
+		want   int
+	}{
+		{"loopback no origin", "127.0.0.1:7391", "", 401},
+		{"localhost origin", "localhost:7391", "http://localhost:3000", 401},
+		{"ipv6 loopback", "[::1]:7391", "http://[::1]:3000", 401},
+		{"allowed host", "devbox.local:7391", "https://devbox.local", 401},
+		{"rebinding matches host", "evil.example:7391", "http://evil.example:7391", 403},
+		{"rebinding no origin", "evil.example", "", 403},
+		{"foreign origin", "127.0.0.1:7391", "http://evil.example", 403},
+		{"bad origin", "127.0.0.1:7391", "http://%zz", 403},
+	}
+
+	handler := NewServer(nil).HTTPHandler(HTTPConfig{Token: "secret", AllowedHosts: []string{"DevBox.local"}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp", strings.NewReader("{}"))
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestListenAndServeRequiresTokenOffLoopback(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+	}{
+		{"all interfaces", "0.0.0.0:0"},
+		{"empty host", ":0"},
+		{"lan address", "192.0.2.10:0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewServer(nil).ListenAndServe(HTTPConfig{Addr: tt.addr})
+			if err == nil || !strings.Contains(err.Error(), "without a bearer token") {
+				t.Errorf("Expected a missing token error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPSessionExpiry(t *testing.T) {
+	server := NewServer(log.New(io.Discard, "", 0))
+	handler := server.HTTPHandler(HTTPConfig{SessionIdle: 50 * time.Millisecond})
+	post := func(session, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/mcp", strings.NewReader(body))
+		req.Host = "127.0.0.1:7391"
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if session != "" {
+			req.Header.Set(sessionHeader, session)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	initialize := func() string {
+		rec := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+		if rec.Code != 200 || rec.Header().Get(sessionHeader) == "" {
+			t.Fatalf("Expected a new session, got status %d", rec.Code)
+		}
+		return rec.Header().Get(sessionHeader)
+	}
+	const ping = `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	active := initialize()
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		if rec := post(active, ping); rec.Code != 200 {
+			t.Fatalf("Expected an active session to stay open, got status %d", rec.Code)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if rec := post(active, ping); rec.Code != 404 {
+		t.Errorf("Expected status 404 for an idle session, got %d", rec.Code)
+	}
+
+	abandoned := initialize()
+	time.Sleep(100 * time.Millisecond)
+	initialize()
+	if server.lookupSession(abandoned) != nil {
+		t.Error("Expected a new session to sweep out an abandoned one")
+	}
+}
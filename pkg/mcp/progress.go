@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type progressKey struct{}
+
+// Progress sends notifications/progress for a single tool call.
+// A nil *Progress is valid and discards all reports.
+type Progress struct {
+	token interface{}
+	send  func(v interface{})
+	start time.Time
+
+	mu   sync.Mutex
+	last float64
+}
+
+// Notification represents an MCP notification
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// ProgressFromContext returns the progress reporter of the current tool call,
+// or nil when the client did not supply a progress token
+func ProgressFromContext(ctx context.Context) *Progress {
+	p, _ := ctx.Value(progressKey{}).(*Progress)
+	return p
+}
+
+func withProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// Elapsed returns the time since the tool call started
+func (p *Progress) Elapsed() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return time.Since(p.start)
+}
+
+// Report sends a progress notification; total may be 0 when unknown.
+// Reports that would move progress backwards are dropped.
+func (p *Progress) Report(progress, total float64, message string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if progress <= p.last {
+		return
+	}
+	p.last = progress
+
+	params := map[string]interface{}{
+		"progressToken": p.token,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+
+	p.send(Notification{
+		JSONRPC: "2.0",
+		Method:  "notifications/progress",
+		Params:  params,
+	})
+}
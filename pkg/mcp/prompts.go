@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PromptArgument describes an argument accepted by a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptHandler renders a prompt from its arguments
+type PromptHandler func(ctx context.Context, args map[string]string) (*PromptResult, error)
+
+// Prompt represents an MCP prompt template
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Handler     PromptHandler    `json:"-"`
+}
+
+// PromptResult is the rendered prompt returned by prompts/get
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a single message of a rendered prompt
+type PromptMessage struct {
+	Role    string      `json:"role"`
+	Content TextContent `json:"content"`
+}
+
+// TextContent is a plain text content block
+type TextContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// UserMessage builds a prompt message sent as the user
+func UserMessage(text string) PromptMessage {
+	return PromptMessage{Role: "user", Content: TextContent{Type: "text", Text: text}}
+}
+
+// RegisterPrompt registers a new prompt
+func (s *Server) RegisterPrompt(name, description string, args []PromptArgument, handler PromptHandler) {
+	s.prompts[name] = &Prompt{
+		Name:        name,
+		Description: description,
+		Arguments:   args,
+		Handler:     handler,
+	}
+}
+
+func (s *Server) handlePromptsList(x *exchange) {
+	prompts := make([]*Prompt, 0, len(s.prompts))
+	for _, prompt := range s.prompts {
+		prompts = append(prompts, prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	x.respond(map[string]interface{}{
+		"prompts": prompts,
+	})
+}
+
+func (s *Server) handlePromptsGet(ctx context.Context, x *exchange) {
+	var params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(x.req.Params, &params); err != nil {
+		x.fail(-32602, fmt.Sprintf("Invalid params: %v", err))
+		return
+	}
+
+	prompt, exists := s.prompts[params.Name]
+	if !exists {
+		x.fail(-32602, fmt.Sprintf("Prompt not found: %s", params.Name))
+		return
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = make(map[string]string)
+	}
+	for _, arg := range prompt.Arguments {
+		if arg.Required && params.Arguments[arg.Name] == "" {
+			x.fail(-32602, fmt.Sprintf("Missing required argument for prompt %s: %s", prompt.Name, arg.Name))
+			return
+		}
+	}
+
+	result, err := prompt.Handler(ctx, params.Arguments)
+	if err != nil {
+		s.logger.Printf("ERROR: Prompt %s failed: %v", prompt.Name, err)
+		x.fail(-32603, fmt.Sprintf("Prompt error: %v", err))
+		return
+	}
+	x.respond(result)
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/json"
+)
+
+// Protocol revisions supported by the server, newest first
+const (
+	ProtocolVersion20250618 = "2025-06-18"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20241105 = "2024-11-05"
+)
+
+var supportedVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// ToolAnnotations are behavioural hints about a tool (protocol 2025-03-26+)
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
+}
+
+// negotiateVersion picks the client's requested version when supported and
+// otherwise the newest version the server speaks
+func negotiateVersion(requested string) string {
+	for _, version := range supportedVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return supportedVersions[0]
+}
+
+// atLeast reports whether a negotiated version includes a given revision;
+// revision dates compare lexically
+func atLeast(version, revision string) bool {
+	return version >= revision
+}
+
+func (s *Server) handleInitialize(x *exchange) {
+	var params struct {
+		ProtocolVersion string                     `json:"protocolVersion"`
+		Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		ClientInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"clientInfo"`
+	}
+	if len(x.req.Params) > 0 {
+		if err := json.Unmarshal(x.req.Params, &params); err != nil {
+			x.fail(-32602, "Invalid params: "+err.Error())
+			return
+		}
+	}
+
+	version := negotiateVersion(params.ProtocolVersion)
+	x.session.initialize(version, params.Capabilities)
+	s.logger.Printf("MCP server initialized for %s %s (protocol %s, requested %q)",
+		params.ClientInfo.Name, params.ClientInfo.Version, version, params.ProtocolVersion)
+
+	x.respond(map[string]interface{}{
+		"protocolVersion": version,
+		"serverInfo": map[string]interface{}{
+			"name":    "git-guardian-mcp",
+			"version": "1.0.0",
+		},
+		"capabilities": s.capabilities(),
+	})
+}
+
+// capabilities advertises only the features that have something registered
+func (s *Server) capabilities() map[string]interface{} {
+	caps := map[string]interface{}{}
+	if len(s.tools) > 0 {
+		caps["tools"] = map[string]interface{}{}
+	}
+	if len(s.resources) > 0 {
+		caps["resources"] = map[string]interface{}{
+			"subscribe":   true,
+			"listChanged": true,
+		}
+	}
+	if len(s.prompts) > 0 {
+		caps["prompts"] = map[string]interface{}{}
+	}
+	return caps
+}
+
+// forVersion returns the tool definition as seen by a client on the given
+// protocol version, dropping fields that revision does not know about
+func (t *Tool) forVersion(version string) *Tool {
+	view := *t
+	if !atLeast(version, ProtocolVersion20250618) {
+		view.Title = ""
+		view.OutputSchema = nil
+	}
+	if !atLeast(version, ProtocolVersion20250326) {
+		view.Annotations = nil
+	}
+	return &view
+}
@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Resource describes a concrete resource the client can read
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a family of resources addressed by an RFC 6570 URI template
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is the text body of a resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// ResourceLister returns the resources of a template that currently exist
+type ResourceLister func(ctx context.Context) []Resource
+
+// ResourceReader reads a resource given the variables extracted from its URI
+type ResourceReader func(ctx context.Context, uri string, vars map[string]string) (*ResourceContents, error)
+
+// ErrResourceNotFound is returned by readers when a resource does not exist
+var ErrResourceNotFound = fmt.Errorf("resource not found")
+
+// InvalidParamsError is returned by readers to reject a URI they won't serve
+type InvalidParamsError struct {
+	Message string
+}
+
+func (e *InvalidParamsError) Error() string { return e.Message }
+
+// resourceProvider serves every resource matching a template
+type resourceProvider struct {
+	template ResourceTemplate
+	pattern  *regexp.Regexp
+	vars     []string
+	list     ResourceLister
+	read     ResourceReader
+}
+
+var templateVar = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// RegisterResourceTemplate registers a resource family; list may be nil when
+// the concrete resources can't be enumerated
+func (s *Server) RegisterResourceTemplate(tmpl ResourceTemplate, list ResourceLister, read ResourceReader) {
+	provider := &resourceProvider{template: tmpl, list: list, read: read}
+
+	pattern := "^"
+	last := 0
+	for _, loc := range templateVar.FindAllStringSubmatchIndex(tmpl.URITemplate, -1) {
+		pattern += regexp.QuoteMeta(tmpl.URITemplate[last:loc[0]]) + "([^/]+)"
+		provider.vars = append(provider.vars, tmpl.URITemplate[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(tmpl.URITemplate[last:]) + "$"
+	provider.pattern = regexp.MustCompile(pattern)
+
+	s.resources = append(s.resources, provider)
+}
+
+// ExpandURI fills a URI template, percent-encoding each value as one path segment
+func ExpandURI(template string, vars map[string]string) string {
+	return templateVar.ReplaceAllStringFunc(template, func(match string) string {
+		return url.PathEscape(vars[strings.Trim(match, "{}")])
+	})
+}
+
+// NotifyResourceUpdated tells every session subscribed to uri that it changed
+func (s *Server) NotifyResourceUpdated(uri string) {
+	for _, sess := range s.sessionList() {
+		if sess.subscribed(uri) {
+			sess.sendNotification(Notification{
+				JSONRPC: "2.0",
+				Method:  "notifications/resources/updated",
+				Params:  map[string]interface{}{"uri": uri},
+			})
+		}
+	}
+}
+
+// NotifyResourceListChanged tells every session that resources were added or removed
+func (s *Server) NotifyResourceListChanged() {
+	for _, sess := range s.sessionList() {
+		sess.sendNotification(Notification{
+			JSONRPC: "2.0",
+			Method:  "notifications/resources/list_changed",
+		})
+	}
+}
+
+func (p *resourceProvider) match(uri string) (map[string]string, bool) {
+	m := p.pattern.FindStringSubmatch(uri)
+	if m == nil {
+		return nil, false
+	}
+	vars := make(map[string]string, len(p.vars))
+	for i, name := range p.vars {
+		value, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		vars[name] = value
+	}
+	return vars, true
+}
+
+func (s *Server) handleResourcesList(ctx context.Context, x *exchange) {
+	resources := make([]Resource, 0)
+	for _, provider := range s.resources {
+		if provider.list != nil {
+			resources = append(resources, provider.list(ctx)...)
+		}
+	}
+	x.respond(map[string]interface{}{
+		"resources": resources,
+	})
+}
+
+func (s *Server) handleResourceTemplatesList(x *exchange) {
+	templates := make([]ResourceTemplate, 0, len(s.resources))
+	for _, provider := range s.resources {
+		templates = append(templates, provider.template)
+	}
+	x.respond(map[string]interface{}{
+		"resourceTemplates": templates,
+	})
+}
+
+func (s *Server) handleResourcesRead(ctx context.Context, x *exchange) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(x.req.Params, &params); err != nil || params.URI == "" {
+		x.fail(-32602, "Invalid params: uri is required")
+		return
+	}
+
+	for _, provider := range s.resources {
+		vars, ok := provider.match(params.URI)
+		if !ok {
+			continue
+		}
+
+		contents, err := provider.read(ctx, params.URI, vars)
+		if err == ErrResourceNotFound {
+			break
+		}
+		var invalid *InvalidParamsError
+		if errors.As(err, &invalid) {
+			x.fail(-32602, "Invalid params: "+invalid.Message)
+			return
+		}
+		if err != nil {
+			x.fail(-32603, fmt.Sprintf("Failed to read resource: %v", err))
+			return
+		}
+		x.respond(map[string]interface{}{
+			"contents": []*ResourceContents{contents},
+		})
+		return
+	}
+
+	resp := errorResponse(x.req.ID, -32002, fmt.Sprintf("Resource not found: %s", params.URI))
+	resp.Error.Data = map[string]interface{}{"uri": params.URI}
+	x.send(resp)
+}
+
+func (s *Server) handleResourceSubscription(x *exchange, subscribe bool) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(x.req.Params, &params); err != nil || params.URI == "" {
+		x.fail(-32602, "Invalid params: uri is required")
+		return
+	}
+
+	x.session.setSubscribed(params.URI, subscribe)
+	x.respond(map[string]interface{}{})
+}
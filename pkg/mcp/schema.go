@@ -0,0 +1,333 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to describe tool arguments
+type Schema struct {
+	Type                 string             `json:"type"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// ValidationError describes an argument that does not match its schema
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// SchemaFor builds a JSON Schema from a struct value or type.
+//
+// Fields are named by their json tag and documented with the optional
+// `description`, `default`, `enum` (comma separated) and `required:"true"` tags.
+func SchemaFor(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{Type: "object", Properties: map[string]*Schema{}}
+	}
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	t := reflect.TypeOf(v)
+	if rt, ok := v.(reflect.Type); ok {
+		t = rt
+	}
+	return schemaForType(t)
+}
+
+func schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return schemaForStruct(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func schemaForStruct(t reflect.Type) *Schema {
+	closed := false
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &closed,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		prop := schemaForType(field.Type)
+		prop.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			for _, value := range strings.Split(enum, ",") {
+				prop.Enum = append(prop.Enum, parseTagValue(prop.Type, strings.TrimSpace(value)))
+			}
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			prop.Default = parseTagValue(prop.Type, def)
+		}
+		if field.Tag.Get("required") == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
+
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func parseTagValue(typ, value string) interface{} {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "array":
+		if value == "" {
+			return []interface{}{}
+		}
+		items := make([]interface{}, 0)
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+		return items
+	}
+	return value
+}
+
+// Validate checks raw arguments against the schema and returns them with
+// defaults applied for any missing properties
+func (s *Schema) Validate(raw json.RawMessage) (json.RawMessage, error) {
+	var value interface{} = map[string]interface{}{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, &ValidationError{Message: fmt.Sprintf("arguments must be valid JSON: %v", err)}
+		}
+	}
+
+	if err := s.validateValue("", value); err != nil {
+		return nil, err
+	}
+	s.applyDefaults(value)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	return data, nil
+}
+
+func (s *Schema) validateValue(path string, value interface{}) error {
+	if s.Type != "" && !matchesType(s.Type, value) {
+		return &ValidationError{
+			Field:   fieldName(path),
+			Message: fmt.Sprintf("expected %s, got %s", s.Type, typeName(value)),
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		return &ValidationError{
+			Field:   fieldName(path),
+			Message: fmt.Sprintf("must be one of %s", enumList(s.Enum)),
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(path, v)
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			if err := s.Items.validateValue(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateObject(path string, obj map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &ValidationError{Field: joinPath(path, name), Message: "is required"}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prop, ok := s.Properties[key]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return &ValidationError{Field: joinPath(path, key), Message: "unknown field"}
+			}
+			continue
+		}
+		if err := prop.validateValue(joinPath(path, key), obj[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) applyDefaults(value interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name, prop := range s.Properties {
+		current, exists := obj[name]
+		if !exists || current == nil {
+			if prop.Default != nil {
+				obj[name] = prop.Default
+			}
+			continue
+		}
+		prop.applyDefaults(current)
+	}
+}
+
+func matchesType(typ string, value interface{}) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return true
+}
+
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	values := make([]string, 0, len(enum))
+	for _, value := range enum {
+		values = append(values, fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+	return strings.Join(values, ", ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "arguments"
+	}
+	return path
+}
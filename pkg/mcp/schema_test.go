@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type schemaTestInput struct {
+	Path    string   `json:"path" required:"true"`
+	Remote  string   `json:"remote" default:"origin"`
+	Mode    string   `json:"mode" enum:"fast,full" default:"fast"`
+	Depth   int      `json:"depth"`
+	Verbose bool     `json:"verbose"`
+	Files   []string `json:"files"`
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  string
+		want  string
+		field string
+	}{
+		{"defaults applied", `{"path":"."}`, `{"mode":"fast","path":".","remote":"origin"}`, ""},
+		{"explicit values kept", `{"path":".","remote":"upstream","mode":"full","depth":3}`, `{"depth":3,"mode":"full","path":".","remote":"upstream"}`, ""},
+		{"null value", `{"path":".","remote":null}`, "", "remote"},
+		{"missing required", `{}`, "", "path"},
+		{"null arguments", `null`, "", "path"},
+		{"wrong type", `{"path":1}`, "", "path"},
+		{"fractional integer", `{"path":".","depth":1.5}`, "", "depth"},
+		{"not in enum", `{"path":".","mode":"slow"}`, "", "mode"},
+		{"unknown field", `{"path":".","branch":"main"}`, "", "branch"},
+		{"bad array item", `{"path":".","files":["a",2]}`, "", "files[1]"},
+		{"not an object", `[]`, "", "arguments"},
+		{"invalid json", `{`, "", ""},
+	}
+
+	schema := SchemaFor(schemaTestInput{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Validate(json.RawMessage(tt.args))
+			if tt.want != "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("got %s, want %s", got, tt.want)
+				}
+				return
+			}
+
+			verr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+			if verr.Field != tt.field {
+				t.Errorf("got field %q, want %q (%v)", verr.Field, tt.field, verr)
+			}
+		})
+	}
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor(schemaTestInput{})
+
+	if len(schema.Required) != 1 || schema.Required[0] != "path" {
+		t.Errorf("got required %v, want [path]", schema.Required)
+	}
+	if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+		t.Errorf("struct schemas must not allow additional properties")
+	}
+	if got := schema.Properties["files"]; got.Type != "array" || got.Items.Type != "string" {
+		t.Errorf("got files schema %+v, want an array of strings", got)
+	}
+	if got := schema.Properties["depth"].Type; got != "integer" {
+		t.Errorf("got depth type %q, want integer", got)
+	}
+	if got := schema.Properties["mode"].Enum; len(got) != 2 || got[0] != "fast" || got[1] != "full" {
+		t.Errorf("got mode enum %v, want [fast full]", got)
+	}
+}
@@ -1,27 +1,39 @@
 package mcp
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"os"
+	"sort"
+	"sync"
+	"time"
 )
 
+// ToolHandler executes a tool call; ctx is cancelled when the client cancels the request
+type ToolHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
 // Tool represents an MCP tool
 type Tool struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Handler     func(params json.RawMessage) (interface{}, error)
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description"`
+	InputSchema  *Schema          `json:"inputSchema"`
+	OutputSchema *Schema          `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+	Handler      ToolHandler      `json:"-"`
 }
 
-// Server represents the MCP server
+// Server represents the MCP server. The tool registry is shared by every
+// transport and session.
 type Server struct {
-	tools  map[string]*Tool
-	logger *log.Logger
-	reader *bufio.Reader
-	writer io.Writer
+	tools     map[string]*Tool
+	prompts   map[string]*Prompt
+	resources []*resourceProvider
+	logger    *log.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*session
 }
 
 // Request represents an MCP request
@@ -42,74 +54,113 @@ type Response struct {
 
 // Error represents an MCP error
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// exchange is a single client message together with where its replies go
+type exchange struct {
+	session *session
+	req     *Request
+	send    func(msg interface{})
 }
 
 // NewServer creates a new MCP server
 func NewServer(logger *log.Logger) *Server {
 	return &Server{
-		tools:  make(map[string]*Tool),
-		logger: logger,
-		reader: bufio.NewReader(os.Stdin),
-		writer: os.Stdout,
+		tools:    make(map[string]*Tool),
+		prompts:  make(map[string]*Prompt),
+		logger:   logger,
+		sessions: make(map[string]*session),
 	}
 }
 
-// RegisterTool registers a new tool
-func (s *Server) RegisterTool(name, description string, handler func(params json.RawMessage) (interface{}, error)) {
-	s.tools[name] = &Tool{
+// RegisterTool registers a new tool. Its arguments are described by input and
+// its structured result by output, each either a struct value (see SchemaFor)
+// or a *Schema; output may be nil for tools without structured results.
+// The returned tool may be further described, e.g. with Annotations.
+func (s *Server) RegisterTool(name, description string, input, output interface{}, handler ToolHandler) *Tool {
+	tool := &Tool{
 		Name:        name,
 		Description: description,
+		InputSchema: SchemaFor(input),
 		Handler:     handler,
 	}
+	if output != nil {
+		tool.OutputSchema = SchemaFor(output)
+	}
+	s.tools[name] = tool
+	return tool
 }
 
-// Start starts the MCP server
-func (s *Server) Start() error {
-	s.logger.Println("MCP server starting...")
-
-	for {
-		line, err := s.reader.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
-				return nil
-			}
-			return fmt.Errorf("read error: %w", err)
-		}
+// handleMessage decodes a single JSON-RPC message and dispatches it
+func (s *Server) handleMessage(ctx context.Context, sess *session, data []byte, send func(msg interface{})) {
+	var msg struct {
+		Request
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		send(errorResponse(nil, -32700, fmt.Sprintf("Parse error: %v", err)))
+		return
+	}
 
-		var req Request
-		if err := json.Unmarshal(line, &req); err != nil {
-			s.sendError(nil, -32700, fmt.Sprintf("Parse error: %v", err))
-			continue
+	// Replies to server-initiated requests such as elicitation/create
+	if msg.Method == "" && msg.ID != nil {
+		if !sess.deliver(msg.ID, clientResponse{Result: msg.Result, Error: msg.Error}) {
+			s.logger.Printf("Ignoring response to unknown request %v", msg.ID)
 		}
-
-		s.handleRequest(&req)
+		return
 	}
+
+	s.handleRequest(ctx, &exchange{session: sess, req: &msg.Request, send: send})
 }
 
-func (s *Server) handleRequest(req *Request) {
+func (s *Server) handleRequest(ctx context.Context, x *exchange) {
+	req := x.req
+
 	// Only log non-routine methods to reduce noise
 	if req.Method != "resources/list" && req.Method != "prompts/list" && req.Method != "ping" {
 		s.logger.Printf("Handling method: %s", req.Method)
 	}
 
+	// Only initialize and ping are allowed before the handshake
+	if !x.session.isInitialized() && req.Method != "initialize" && req.Method != "ping" {
+		if req.ID != nil {
+			x.fail(-32600, fmt.Sprintf("Server not initialized: send initialize before %s", req.Method))
+		}
+		return
+	}
+
 	switch req.Method {
 	case "initialize":
-		s.handleInitialize(req)
+		s.handleInitialize(x)
 	case "initialized", "notifications/initialized":
 		// Notification - no response needed
 		return
 	case "tools/list":
-		s.handleToolsList(req)
+		s.handleToolsList(x)
 	case "tools/call":
-		s.handleToolCall(req)
+		s.handleToolCall(ctx, x)
+	case "notifications/cancelled":
+		s.handleCancelled(x)
 	case "resources/list":
-		s.handleResourcesList(req)
+		s.handleResourcesList(ctx, x)
+	case "resources/templates/list":
+		s.handleResourceTemplatesList(x)
+	case "resources/read":
+		s.handleResourcesRead(ctx, x)
+	case "resources/subscribe":
+		s.handleResourceSubscription(x, true)
+	case "resources/unsubscribe":
+		s.handleResourceSubscription(x, false)
 	case "prompts/list":
-		s.handlePromptsList(req)
+		s.handlePromptsList(x)
+	case "prompts/get":
+		s.handlePromptsGet(ctx, x)
 	case "ping":
-		s.sendResponse(req.ID, map[string]interface{}{})
+		x.respond(map[string]interface{}{})
 	default:
 		// Don't send error responses for notifications (methods without IDs)
 		if req.ID == nil {
@@ -117,66 +168,49 @@ func (s *Server) handleRequest(req *Request) {
 			return
 		}
 		s.logger.Printf("Unknown method: %s", req.Method)
-		s.sendError(req.ID, -32601, fmt.Sprintf("Method not found: %s", req.Method))
-	}
-}
-
-func (s *Server) handleInitialize(req *Request) {
-	s.logger.Println("MCP server initialized")
-	result := map[string]interface{}{
-		"protocolVersion": "2024-11-05",
-		"serverInfo": map[string]interface{}{
-			"name":    "git-guardian-mcp",
-			"version": "1.0.0",
-		},
-		"capabilities": map[string]interface{}{
-			"tools":     map[string]interface{}{},
-			"resources": map[string]interface{}{},
-			"prompts":   map[string]interface{}{},
-		},
+		x.fail(-32601, fmt.Sprintf("Method not found: %s", req.Method))
 	}
-	s.sendResponse(req.ID, result)
 }
 
-func (s *Server) handleToolsList(req *Request) {
-	tools := make([]map[string]interface{}, 0, len(s.tools))
+func (s *Server) handleToolsList(x *exchange) {
+	version := x.session.version()
+	tools := make([]*Tool, 0, len(s.tools))
 	for _, tool := range s.tools {
-		tools = append(tools, map[string]interface{}{
-			"name":        tool.Name,
-			"description": tool.Description,
-			"inputSchema": map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
-		})
+		tools = append(tools, tool.forVersion(version))
 	}
-	s.sendResponse(req.ID, map[string]interface{}{
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	x.respond(map[string]interface{}{
 		"tools": tools,
 	})
 }
 
-func (s *Server) handleResourcesList(req *Request) {
-	// No resources for now
-	s.sendResponse(req.ID, map[string]interface{}{
-		"resources": []interface{}{},
-	})
-}
+func (s *Server) handleCancelled(x *exchange) {
+	var params struct {
+		RequestID interface{} `json:"requestId"`
+		Reason    string      `json:"reason"`
+	}
+	if err := json.Unmarshal(x.req.Params, &params); err != nil || params.RequestID == nil {
+		return
+	}
 
-func (s *Server) handlePromptsList(req *Request) {
-	// No prompts for now
-	s.sendResponse(req.ID, map[string]interface{}{
-		"prompts": []interface{}{},
-	})
+	if x.session.cancel(params.RequestID) {
+		s.logger.Printf("Cancelling request %v: %s", params.RequestID, params.Reason)
+	}
 }
 
-func (s *Server) handleToolCall(req *Request) {
+func (s *Server) handleToolCall(ctx context.Context, x *exchange) {
 	var params struct {
 		Name      string          `json:"name"`
 		Arguments json.RawMessage `json:"arguments"`
+		Meta      struct {
+			ProgressToken interface{} `json:"progressToken"`
+		} `json:"_meta"`
 	}
 
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		s.sendError(req.ID, -32602, fmt.Sprintf("Invalid params: %v", err))
+	if err := json.Unmarshal(x.req.Params, &params); err != nil {
+		x.fail(-32602, fmt.Sprintf("Invalid params: %v", err))
 		return
 	}
 
@@ -184,38 +218,75 @@ func (s *Server) handleToolCall(req *Request) {
 
 	tool, exists := s.tools[params.Name]
 	if !exists {
-		s.sendError(req.ID, -32602, fmt.Sprintf("Tool not found: %s", params.Name))
+		x.fail(-32602, fmt.Sprintf("Tool not found: %s", params.Name))
 		return
 	}
 
-	result, err := tool.Handler(params.Arguments)
+	args, err := tool.InputSchema.Validate(params.Arguments)
 	if err != nil {
+		x.failInvalidArguments(params.Name, err)
+		return
+	}
+
+	if params.Meta.ProgressToken != nil {
+		ctx = withProgress(ctx, &Progress{
+			token: params.Meta.ProgressToken,
+			send:  x.send,
+			start: time.Now(),
+		})
+	}
+
+	ctx = withCall(ctx, &callInfo{session: x.session, send: x.send, canStream: !noStream(ctx)})
+	result, err := tool.Handler(ctx, args)
+	if ctx.Err() != nil {
+		// Cancelled requests must not receive a response
+		s.logger.Printf("Tool %s cancelled", params.Name)
+		return
+	}
+	if err != nil {
+		// Tool failures are results the model can read, not protocol errors
 		s.logger.Printf("ERROR: Tool %s failed: %v", params.Name, err)
-		s.sendError(req.ID, -32603, fmt.Sprintf("Tool execution error: %v", err))
+		x.respond(map[string]interface{}{
+			"content": []TextContent{{Type: "text", Text: err.Error()}},
+			"isError": true,
+		})
 		return
 	}
 
-	s.sendResponse(req.ID, map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": toJSON(result),
-			},
-		},
-	})
+	response := map[string]interface{}{
+		"content": []TextContent{{Type: "text", Text: ToJSON(result)}},
+	}
+	if tool.OutputSchema != nil && atLeast(x.session.version(), ProtocolVersion20250618) {
+		response["structuredContent"] = result
+	}
+	x.respond(response)
 }
 
-func (s *Server) sendResponse(id interface{}, result interface{}) {
-	resp := Response{
+func (x *exchange) respond(result interface{}) {
+	x.send(Response{
 		JSONRPC: "2.0",
-		ID:      id,
+		ID:      x.req.ID,
 		Result:  result,
+	})
+}
+
+func (x *exchange) fail(code int, message string) {
+	x.send(errorResponse(x.req.ID, code, message))
+}
+
+func (x *exchange) failInvalidArguments(tool string, err error) {
+	resp := errorResponse(x.req.ID, -32602, fmt.Sprintf("Invalid arguments for tool %s: %v", tool, err))
+	if verr, ok := err.(*ValidationError); ok {
+		resp.Error.Data = map[string]interface{}{
+			"field":  verr.Field,
+			"reason": verr.Message,
+		}
 	}
-	s.writeResponse(resp)
+	x.send(resp)
 }
 
-func (s *Server) sendError(id interface{}, code int, message string) {
-	resp := Response{
+func errorResponse(id interface{}, code int, message string) Response {
+	return Response{
 		JSONRPC: "2.0",
 		ID:      id,
 		Error: &Error{
@@ -223,22 +294,16 @@ func (s *Server) sendError(id interface{}, code int, message string) {
 			Message: message,
 		},
 	}
-	s.writeResponse(resp)
 }
 
-func (s *Server) writeResponse(resp Response) {
-	data, err := json.Marshal(resp)
-	if err != nil {
-		s.logger.Printf("Failed to marshal response: %v", err)
-		return
-	}
-	data = append(data, '\n')
-	if _, err := s.writer.Write(data); err != nil {
-		s.logger.Printf("Failed to write response: %v", err)
-	}
+// requestKey normalizes a JSON-RPC id so numeric and string ids don't collide
+func requestKey(id interface{}) string {
+	data, _ := json.Marshal(id)
+	return string(data)
 }
 
-func toJSON(v interface{}) string {
+// ToJSON renders v as indented JSON, falling back to its %v form
+func ToJSON(v interface{}) string {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Sprintf("%v", v)
@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// session holds per-client state shared by every request of one connection
+type session struct {
+	id    string
+	local bool // stdio session with the process that started the server
+
+	mu            sync.Mutex
+	inflight      map[string]context.CancelFunc
+	notify        func(msg interface{})
+	subscriptions map[string]bool
+
+	initialized     bool
+	protocolVersion string
+	clientCaps      map[string]json.RawMessage
+
+	lastSeen time.Time // last HTTP request or stream close
+
+	nextID    int
+	pending   map[string]chan clientResponse
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// clientResponse is the client's reply to a server-initiated request
+type clientResponse struct {
+	Result json.RawMessage
+	Error  *Error
+}
+
+func newSession(id string) *session {
+	return &session{
+		id:            id,
+		inflight:      make(map[string]context.CancelFunc),
+		subscriptions: make(map[string]bool),
+		pending:       make(map[string]chan clientResponse),
+		done:          make(chan struct{}),
+		lastSeen:      time.Now(),
+	}
+}
+
+// touch records client activity on the session
+func (s *session) touch() {
+	s.mu.Lock()
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+// idle reports whether an HTTP session has had no request in flight, no open
+// stream and no client activity for longer than timeout
+func (s *session) idle(timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.local || len(s.inflight) > 0 || s.notify != nil {
+		return false
+	}
+	return time.Since(s.lastSeen) > timeout
+}
+
+// initialize records the negotiated protocol version and client capabilities
+func (s *session) initialize(version string, caps map[string]json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initialized = true
+	s.protocolVersion = version
+	s.clientCaps = caps
+}
+
+func (s *session) isInitialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized
+}
+
+func (s *session) version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.protocolVersion
+}
+
+func (s *session) supportsElicitation() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.clientCaps["elicitation"]
+	return ok && atLeast(s.protocolVersion, ProtocolVersion20250618)
+}
+
+// request sends a server-initiated request and waits for the client's reply
+func (s *session) request(ctx context.Context, send func(msg interface{}), method string, params interface{}) (json.RawMessage, error) {
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("guardian-%d", s.nextID)
+	key := requestKey(id)
+	reply := make(chan clientResponse, 1)
+	s.pending[key] = reply
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+	}()
+
+	send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, fmt.Errorf("%s failed: client disconnected", method)
+	case resp := <-reply:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, resp.Error.Message)
+		}
+		return resp.Result, nil
+	}
+}
+
+// deliver routes a client response to the request waiting for it
+func (s *session) deliver(id interface{}, resp clientResponse) bool {
+	s.mu.Lock()
+	reply, ok := s.pending[requestKey(id)]
+	s.mu.Unlock()
+
+	if ok {
+		reply <- resp
+	}
+	return ok
+}
+
+// track registers an in-flight request and returns a context that is
+// cancelled by notifications/cancelled or when the session closes
+func (s *session) track(parent context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	key := requestKey(id)
+
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancel cancels an in-flight request and reports whether it was found
+func (s *session) cancel(id interface{}) bool {
+	s.mu.Lock()
+	cancel, ok := s.inflight[requestKey(id)]
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// close cancels every in-flight request of the session
+func (s *session) close() {
+	s.endInput()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cancel := range s.inflight {
+		cancel()
+	}
+	s.notify = nil
+}
+
+// endInput marks that no more client messages will arrive, failing any
+// server-initiated request still waiting for a reply
+func (s *session) endInput() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// setNotify sets where server-initiated notifications are delivered
+func (s *session) setNotify(fn func(msg interface{})) {
+	s.mu.Lock()
+	s.notify = fn
+	s.mu.Unlock()
+}
+
+// sendNotification delivers a server-initiated message, dropping it when
+// the client has no open stream
+func (s *session) sendNotification(msg interface{}) {
+	s.mu.Lock()
+	notify := s.notify
+	s.mu.Unlock()
+
+	if notify != nil {
+		notify(msg)
+	}
+}
+
+// setSubscribed records whether the client wants updates for a resource URI
+func (s *session) setSubscribed(uri string, subscribed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if subscribed {
+		s.subscriptions[uri] = true
+	} else {
+		delete(s.subscriptions, uri)
+	}
+}
+
+func (s *session) subscribed(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscriptions[uri]
+}
+
+func newSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+func (s *Server) addSession(sess *session) {
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+}
+
+func (s *Server) removeSession(sess *session) {
+	s.mu.Lock()
+	delete(s.sessions, sess.id)
+	s.mu.Unlock()
+	sess.close()
+}
+
+func (s *Server) lookupSession(id string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id]
+}
+
+// expireSessions removes HTTP sessions whose client went away without
+// sending DELETE
+func (s *Server) expireSessions(timeout time.Duration) {
+	for _, sess := range s.sessionList() {
+		if sess.idle(timeout) {
+			s.removeSession(sess)
+		}
+	}
+}
+
+func (s *Server) sessionList() []*session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// stdioWriter serializes newline-delimited JSON messages onto a stream
+type stdioWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	server *Server
+}
+
+// Start serves MCP over stdin/stdout and returns once stdin is closed and
+// all in-flight tool calls have finished
+func (s *Server) Start() error {
+	return s.ServeStdio(os.Stdin, os.Stdout)
+}
+
+// ServeStdio serves a single session of newline-delimited JSON-RPC over r and w
+func (s *Server) ServeStdio(r io.Reader, w io.Writer) error {
+	s.logger.Println("MCP server starting...")
+
+	out := &stdioWriter{w: w, server: s}
+	sess := newSession(newSessionID())
+	sess.local = true
+	sess.setNotify(out.send)
+	s.addSession(sess)
+	defer s.removeSession(sess)
+
+	var calls sync.WaitGroup
+	defer calls.Wait()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			s.handleLine(sess, &calls, line, out.send)
+		}
+		if err != nil {
+			sess.endInput()
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read error: %w", err)
+		}
+	}
+}
+
+func (s *Server) handleLine(sess *session, calls *sync.WaitGroup, line []byte, send func(msg interface{})) {
+	var probe struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+
+	// Requests such as tools/call or resources/read may run git or tests for
+	// minutes, so they are dispatched concurrently and can be cancelled. The
+	// handshake, notifications and client responses are handled in order.
+	if json.Unmarshal(line, &probe) == nil && probe.ID != nil && probe.Method != "" && probe.Method != "initialize" {
+		ctx, done := sess.track(context.Background(), probe.ID)
+		calls.Add(1)
+		go func() {
+			defer calls.Done()
+			defer done()
+			s.handleMessage(ctx, sess, line, send)
+		}()
+		return
+	}
+
+	s.handleMessage(context.Background(), sess, line, send)
+}
+
+func (w *stdioWriter) send(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		w.server.logger.Printf("Failed to marshal message: %v", err)
+		return
+	}
+	data = append(data, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.w.Write(data); err != nil {
+		w.server.logger.Printf("Failed to write message: %v", err)
+	}
+}
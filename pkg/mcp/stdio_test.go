@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestServeStdio_SlowRequestsDoNotBlockPingOrCancel(t *testing.T) {
+	server := NewServer(log.New(io.Discard, "", 0))
+	cancelled := make(chan struct{})
+	server.RegisterPrompt("slow", "Blocks until cancelled", nil, func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- server.ServeStdio(serverIn, serverOut) }()
+	replies := make(chan []byte, 16)
+	go func() {
+		scanner := bufio.NewScanner(clientIn)
+		for scanner.Scan() {
+			replies <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(replies)
+	}()
+
+	send := func(msg string) {
+		if _, err := io.WriteString(clientOut, msg+"\n"); err != nil {
+			t.Fatalf("failed to write %s: %v", msg, err)
+		}
+	}
+	read := func() map[string]interface{} {
+		select {
+		case line := <-replies:
+			var reply map[string]interface{}
+			if err := json.Unmarshal(line, &reply); err != nil {
+				t.Fatalf("invalid reply %s: %v", line, err)
+			}
+			return reply
+		case <-time.After(5 * time.Second):
+			t.Fatal("no reply within 5s")
+			return nil
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	read()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"slow"}}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	if reply := read(); reply["id"] != float64(3) {
+		t.Fatalf("expected the ping reply while prompts/get runs, got %v", reply)
+	}
+
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("prompts/get was not cancelled")
+	}
+
+	clientOut.Close()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+)
+
+// Violation is a commit message that breaks a policy rule
+type Violation struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy checks commit messages against configured rules
+type Policy struct {
+	cfg    config.CommitPolicy
+	types  map[string]bool
+	ticket *regexp.Regexp
+}
+
+var (
+	conventionalSubject = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+	wipSubject          = regexp.MustCompile(`(?i)^(?:\[?wip\]?\b|fixup!|squash!|amend!)`)
+)
+
+// New creates a policy from its configuration
+func New(cfg config.CommitPolicy) (*Policy, error) {
+	p := &Policy{cfg: cfg, types: make(map[string]bool)}
+	for _, t := range cfg.Types {
+		p.types[t] = true
+	}
+	if cfg.TicketPattern != "" {
+		re, err := regexp.Compile(cfg.TicketPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket pattern: %w", err)
+		}
+		p.ticket = re
+	}
+	return p, nil
+}
+
+// Blocking reports whether violations should fail validation
+func (p *Policy) Blocking() bool {
+	return p.cfg.Blocking
+}
+
+// Check returns the violations of every commit
+func (p *Policy) Check(commits []git.Commit) []Violation {
+	violations := make([]Violation, 0)
+	for _, commit := range commits {
+		violations = append(violations, p.CheckCommit(commit)...)
+	}
+	return violations
+}
+
+// CheckCommit returns the violations of a single commit
+func (p *Policy) CheckCommit(commit git.Commit) []Violation {
+	violations := make([]Violation, 0)
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Commit:  commit.Hash,
+			Subject: commit.Message,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	subject := commit.Message
+	if p.cfg.ForbidWIP && wipSubject.MatchString(subject) {
+		add("no-wip", "work-in-progress commit must be squashed before pushing")
+		return violations
+	}
+	if strings.HasPrefix(subject, "Merge ") || strings.HasPrefix(subject, "Revert \"") {
+		return violations
+	}
+
+	description := subject
+	if len(p.types) > 0 {
+		m := conventionalSubject.FindStringSubmatch(subject)
+		switch {
+		case m == nil:
+			add("conventional", "subject must follow \"type(scope): description\" with type one of %s", strings.Join(p.cfg.Types, ", "))
+		case !p.types[strings.ToLower(m[1])]:
+			add("conventional", "unknown commit type %q (allowed: %s)", m[1], strings.Join(p.cfg.Types, ", "))
+		case p.cfg.RequireScope && m[2] == "":
+			add("conventional", "commit type %q must have a scope, e.g. %s(api): ...", m[1], m[1])
+		}
+		if m != nil {
+			description = m[4]
+		}
+	}
+
+	if p.cfg.MaxSubjectLength > 0 && len([]rune(subject)) > p.cfg.MaxSubjectLength {
+		add("subject-length", "subject is %d characters, limit is %d", len([]rune(subject)), p.cfg.MaxSubjectLength)
+	}
+
+	if p.cfg.Imperative {
+		if word, ok := nonImperative(description); ok {
+			if verb, ok := suggestImperative(word); ok {
+				add("imperative", "subject should use the imperative mood (%q instead of %q)", verb, word)
+			} else {
+				add("imperative", "subject should use the imperative mood, not %q", word)
+			}
+		}
+	}
+
+	if p.ticket != nil && !p.mentionsTicket(commit) {
+		add("ticket", "message must reference a ticket matching %s", p.cfg.TicketPattern)
+	}
+
+	return violations
+}
+
+func (p *Policy) mentionsTicket(commit git.Commit) bool {
+	if p.ticket.MatchString(commit.Message) || p.ticket.MatchString(commit.Body) {
+		return true
+	}
+	for _, trailer := range commit.Trailers {
+		if p.ticket.MatchString(trailer.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// imperativeVerbs are common commit verbs whose -s, -ed and -ing forms are
+// flagged; words outside the list are flagged only for the -ed and -ing forms
+var imperativeVerbs = map[string]bool{
+	"add": true, "allow": true, "bump": true, "change": true, "clean": true, "create": true,
+	"delete": true, "drop": true, "enable": true, "disable": true, "ensure": true, "fix": true,
+	"handle": true, "implement": true, "improve": true, "introduce": true, "make": true,
+	"merge": true, "move": true, "prevent": true, "refactor": true, "remove": true,
+	"rename": true, "replace": true, "return": true, "revert": true, "set": true,
+	"simplify": true, "support": true, "update": true, "upgrade": true, "use": true,
+}
+
+// notVerbForms are words that look like past or progressive forms but aren't
+var notVerbForms = map[string]bool{
+	"need": true, "embed": true, "feed": true, "seed": true, "speed": true, "shed": true,
+	"proceed": true, "succeed": true, "exceed": true, "during": true,
+	"bring": true, "string": true, "ping": true, "ring": true, "sing": true, "thing": true,
+	"logging": true, "tracing": true, "caching": true, "testing": true, "tooling": true,
+	"red": true, "bed": true,
+}
+
+// nonImperative reports the first word of a description when it is in the
+// past tense, third person or progressive form
+func nonImperative(description string) (string, bool) {
+	fields := strings.Fields(description)
+	if len(fields) == 0 {
+		return "", false
+	}
+	word := strings.ToLower(strings.Trim(fields[0], ".,:;!"))
+	if notVerbForms[word] || imperativeVerbs[word] {
+		return "", false
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		return fields[0], true
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return fields[0], true
+	case strings.HasSuffix(word, "es") && imperativeVerbs[strings.TrimSuffix(word, "es")]:
+		return fields[0], true
+	case strings.HasSuffix(word, "s") && imperativeVerbs[strings.TrimSuffix(word, "s")]:
+		return fields[0], true
+	}
+	return "", false
+}
+
+// suggestImperative guesses the base form of a verb for the violation message
+func suggestImperative(word string) (string, bool) {
+	lower := strings.ToLower(word)
+	for verb := range imperativeVerbs {
+		stem := strings.TrimSuffix(verb, "e")
+		doubled := verb + verb[len(verb)-1:]
+		for _, form := range []string{verb + "s", verb + "es", verb + "ed", verb + "d", verb + "ing", stem + "ing", doubled + "ed", doubled + "ing"} {
+			if lower == form {
+				return matchCase(word, verb), true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchCase capitalizes verb when word is capitalized
+func matchCase(word, verb string) string {
+	if word != "" && strings.ToUpper(word[:1]) == word[:1] {
+		return strings.ToUpper(verb[:1]) + verb[1:]
+	}
+	return verb
+}
@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+)
+
+func TestNonImperative(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"Added retry to the client", "Added"},
+		{"fixes the flaky test", "fixes"},
+		{"Updating dependencies", "Updating"},
+		{"Uses the cached token", "Uses"},
+		{"Handles empty input", "Handles"},
+		{"Refactored parser", "Refactored"},
+		{"Add retry to the client", ""},
+		{"fix the flaky test", ""},
+		{"Need a lock around the map", ""},
+		{"Speed up startup", ""},
+		{"Proceed when the remote is gone", ""},
+		{"String escaping in logs", ""},
+		{"Pushes are retried", ""},
+		{"Bed", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			word, ok := nonImperative(tt.description)
+			if ok != (tt.want != "") || word != tt.want {
+				t.Errorf("got %q, %v, want %q", word, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestImperative(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"Added", "Add"},
+		{"fixes", "fix"},
+		{"Removing", "Remove"},
+		{"moved", "move"},
+		{"Setting", "Set"},
+		{"dropped", "drop"},
+		{"Frobnicated", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			verb, ok := suggestImperative(tt.word)
+			if ok != (tt.want != "") || verb != tt.want {
+				t.Errorf("got %q, %v, want %q", verb, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCommit(t *testing.T) {
+	p, err := New(config.CommitPolicy{
+		Types:            []string{"feat", "fix"},
+		RequireScope:     true,
+		MaxSubjectLength: 40,
+		Imperative:       true,
+		TicketPattern:    `[A-Z]+-[0-9]+`,
+		ForbidWIP:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		commit git.Commit
+		want   []string
+	}{
+		{"valid", git.Commit{Message: "feat(api): add retries ABC-1"}, nil},
+		{"ticket in trailer", git.Commit{Message: "fix(api): handle nil", Trailers: []git.Trailer{{Key: "Refs", Value: "ABC-2"}}}, nil},
+		{"ticket in body", git.Commit{Message: "fix(api): handle nil", Body: "See ABC-3"}, nil},
+		{"wip stops other checks", git.Commit{Message: "WIP stuff"}, []string{"no-wip"}},
+		{"fixup", git.Commit{Message: "fixup! feat(api): add retries"}, []string{"no-wip"}},
+		{"merge", git.Commit{Message: "Merge branch 'main' into feature"}, nil},
+		{"not conventional", git.Commit{Message: "Add retries ABC-1"}, []string{"conventional"}},
+		{"unknown type", git.Commit{Message: "chore(api): add retries ABC-1"}, []string{"conventional"}},
+		{"missing scope", git.Commit{Message: "feat: add retries ABC-1"}, []string{"conventional"}},
+		{"past tense", git.Commit{Message: "feat(api): added retries ABC-1"}, []string{"imperative"}},
+		{"everything", git.Commit{Message: "feat(api): added retries to every client call"}, []string{"subject-length", "imperative", "ticket"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := make([]string, 0)
+			for _, v := range p.CheckCommit(tt.commit) {
+				rules = append(rules, v.Rule)
+			}
+			if len(rules) != len(tt.want) || (len(rules) > 0 && !reflect.DeepEqual(rules, tt.want)) {
+				t.Errorf("got rules %v, want %v", rules, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule detects one kind of secret in an added line
+type Rule struct {
+	ID          string
+	Description string
+	// Pattern matches the secret; its first capture group, when present, is the secret itself
+	Pattern *regexp.Regexp
+	// MinEntropy rejects matches whose Shannon entropy (bits per char) is lower
+	MinEntropy float64
+	// Severity of the rule's findings: error (default) fails the scan, warning doesn't
+	Severity string
+}
+
+// DefaultRules are the built-in detectors
+var DefaultRules = []Rule{
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		MinEntropy:  3.5,
+	},
+	{
+		ID:          "private-key",
+		Description: "private key block",
+		Pattern:     regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{50,255})\b`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`),
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack webhook URL",
+		Pattern:     regexp.MustCompile(`(https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+)`),
+	},
+	{
+		ID:          "generic-secret",
+		Description: "high-entropy value assigned to a secret-like name",
+		Pattern:     regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|api_?key|access_?key|auth|credential)[\w.-]*["']?\s*(?::=|[:=])\s*["']([^\s"']{16,})["']`),
+		MinEntropy:  3.5,
+	},
+	{
+		ID:          "high-entropy-string",
+		Description: "high-entropy string",
+		Pattern:     regexp.MustCompile(`["']([A-Za-z0-9+/=_-]{32,})["']`),
+		MinEntropy:  4.5,
+		Severity:    "warning", // hashes, IDs and test fixtures look the same
+	},
+}
+
+// lockfiles hold integrity hashes and are generated, never hand-edited
+var lockfiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"go.sum":              true,
+	"Cargo.lock":          true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"composer.lock":       true,
+	"Gemfile.lock":        true,
+	"pubspec.lock":        true,
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(len([]rune(s)))
+	bits := 0.0
+	for _, count := range counts {
+		p := float64(count) / n
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// redact keeps the first characters of a secret so it can be recognised
+func redact(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+)
+
+// AllowlistFile is read from the repository root. Each line is a comment
+// (#), "path:<glob>" to skip files, "rule:<id>" to disable a rule, or a
+// regular expression matched against detected secrets
+const AllowlistFile = ".guardian-secrets-allowlist"
+
+// AllowMarker on a line suppresses findings on that line
+const AllowMarker = "guardian:allow-secret"
+
+// Finding is a secret introduced by a commit
+type Finding struct {
+	Commit      string `json:"commit"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	Secret      string `json:"secret,omitempty"` // redacted
+	Severity    string `json:"severity"`         // error or warning
+}
+
+// Scanner finds secrets in the lines added by commits
+type Scanner struct {
+	repoPath     string
+	rules        []Rule
+	paths        []string
+	disabled     map[string]bool
+	allowSecrets []*regexp.Regexp
+}
+
+// NewScanner creates a scanner with the default rules and the repository's allowlist
+func NewScanner(repoPath string) (*Scanner, error) {
+	s := &Scanner{
+		repoPath: repoPath,
+		rules:    DefaultRules,
+		disabled: make(map[string]bool),
+	}
+	if err := s.loadAllowlist(filepath.Join(repoPath, AllowlistFile)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Scanner) loadAllowlist(file string) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secrets allowlist: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "path:"):
+			s.paths = append(s.paths, strings.TrimSpace(strings.TrimPrefix(line, "path:")))
+		case strings.HasPrefix(line, "rule:"):
+			s.disabled[strings.TrimSpace(strings.TrimPrefix(line, "rule:"))] = true
+		default:
+			re, err := regexp.Compile(line)
+			if err != nil {
+				return fmt.Errorf("invalid pattern on line %d of %s: %w", lineNo, AllowlistFile, err)
+			}
+			s.allowSecrets = append(s.allowSecrets, re)
+		}
+	}
+	return scanner.Err()
+}
+
+// ScanDiff scans the lines a unified diff adds, skipping allowlisted paths and lockfiles
+func (s *Scanner) ScanDiff(commit, diff string) []Finding {
+	findings := make([]Finding, 0)
+	file := ""
+	line := 0
+	header := false // between a file's diff --git line and its first hunk
+	envReported := false
+
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			file = ""
+			header = true
+			envReported = false
+		case header && strings.HasPrefix(text, "+++ "):
+			if name := git.DiffPath(strings.TrimPrefix(text, "+++ ")); name != "/dev/null" && !s.skipped(strings.TrimPrefix(name, "b/")) {
+				file = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(text, "@@ "):
+			header = false
+			line = hunkStart(text)
+		case file == "":
+		case strings.HasPrefix(text, "+"):
+			added := text[1:]
+			if isEnvFile(file) && !envReported && !s.disabled["env-file"] {
+				findings = append(findings, s.finding(commit, file, line, Rule{ID: "env-file", Description: ".env file committed"}, ""))
+				envReported = true
+			}
+			if !strings.Contains(added, AllowMarker) {
+				findings = append(findings, s.scanLine(commit, file, line, added)...)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// scanLine applies every rule to one added line, reporting each rule once
+func (s *Scanner) scanLine(commit, file string, line int, text string) []Finding {
+	findings := make([]Finding, 0)
+	reported := make(map[string]bool)
+	for _, rule := range s.rules {
+		if s.disabled[rule.ID] {
+			continue
+		}
+		for _, m := range rule.Pattern.FindAllStringSubmatch(text, -1) {
+			secret := m[0]
+			if len(m) > 1 && m[1] != "" {
+				secret = m[1]
+			}
+			if reported[secret] || entropy(secret) < rule.MinEntropy || s.secretAllowed(secret) {
+				continue
+			}
+			reported[secret] = true
+			findings = append(findings, s.finding(commit, file, line, rule, redact(secret)))
+		}
+	}
+	return findings
+}
+
+func (s *Scanner) finding(commit, file string, line int, rule Rule, secret string) Finding {
+	severity := rule.Severity
+	if severity == "" {
+		severity = "error"
+	}
+	return Finding{
+		Commit:      commit,
+		File:        filepath.Join(s.repoPath, file),
+		Line:        line,
+		Rule:        rule.ID,
+		Description: rule.Description,
+		Secret:      secret,
+		Severity:    severity,
+	}
+}
+
+// skipped reports whether a file is a lockfile or matches an allowlisted path
+func (s *Scanner) skipped(file string) bool {
+	if lockfiles[path.Base(file)] {
+		return true
+	}
+	for _, glob := range s.paths {
+		if ok, _ := path.Match(glob, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scanner) secretAllowed(secret string) bool {
+	for _, re := range s.allowSecrets {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvFile matches .env and .env.<environment>, but not templates such as .env.example
+func isEnvFile(file string) bool {
+	name := path.Base(file)
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	switch path.Ext(name) {
+	case ".example", ".sample", ".template", ".dist", ".defaults":
+		return false
+	}
+	return true
+}
+
+// hunkStart returns the first new-side line of a hunk header "@@ -a,b +c,d @@"
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start := strings.TrimPrefix(fields[2], "+")
+	if i := strings.IndexByte(start, ','); i >= 0 {
+		start = start[:i]
+	}
+	n, _ := strconv.Atoi(start)
+	return n
+}
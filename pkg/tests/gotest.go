@@ -0,0 +1,295 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PackageResult is the outcome of one package of a go test run
+type PackageResult struct {
+	Package  string   `json:"package"`
+	Status   string   `json:"status"`   // passed, failed or skipped
+	Duration float64  `json:"duration"` // in seconds
+	Output   string   `json:"output,omitempty"`
+	Panic    string   `json:"panic,omitempty"`
+	Races    []string `json:"races,omitempty"`
+	Tests    []GoTest `json:"tests,omitempty"`
+}
+
+// GoTest is the outcome of one test function or subtest; output is kept
+// only for failures
+type GoTest struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`   // passed, failed or skipped
+	Duration float64  `json:"duration"` // in seconds
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Panic    string   `json:"panic,omitempty"`
+	Races    []string `json:"races,omitempty"`
+}
+
+// testEvent is one event of the test2json stream printed by go test -json
+type testEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	ImportPath  string // build-output events
+	FailedBuild string // package failed to build because of this import path
+}
+
+// isGoTest reports whether argv runs go test
+func isGoTest(argv []string) bool {
+	return len(argv) > 1 && strings.TrimSuffix(filepath.Base(argv[0]), ".exe") == "go" && argv[1] == "test"
+}
+
+// withJSON adds -json to a go test command unless it is already set
+func withJSON(argv []string) []string {
+	for _, arg := range argv[2:] {
+		if arg == "-json" || strings.HasPrefix(arg, "-json=") || arg == "--json" {
+			return argv
+		}
+	}
+	return append([]string{argv[0], argv[1], "-json"}, argv[2:]...)
+}
+
+var (
+	// failureLocation matches the "    file_test.go:12: message" lines of t.Error and t.Fatal
+	failureLocation = regexp.MustCompile(`^\s+([\w./-]+\.go):(\d+):`)
+	// stackFrame matches a test file frame of a panic or race stack trace
+	stackFrame = regexp.MustCompile(`^\s+(\S+_test\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// raceSeparator closes each race detector report
+const raceSeparator = "=================="
+
+// goTestParser accumulates the events of a go test -json run
+type goTestParser struct {
+	text        strings.Builder
+	packages    []*PackageResult
+	byName      map[string]*PackageResult
+	tests       map[string]*GoTest
+	order       map[string][]string // test keys per package, in start order
+	testOutput  map[string]*strings.Builder
+	pkgOutput   map[string]*strings.Builder
+	buildOutput map[string]*strings.Builder
+}
+
+// parseGoTestJSON decodes go test -json output into per-package results and
+// the plain text go test would have printed
+func parseGoTestJSON(stdout []byte) ([]PackageResult, string) {
+	p := &goTestParser{
+		packages:    make([]*PackageResult, 0),
+		byName:      make(map[string]*PackageResult),
+		tests:       make(map[string]*GoTest),
+		order:       make(map[string][]string),
+		testOutput:  make(map[string]*strings.Builder),
+		pkgOutput:   make(map[string]*strings.Builder),
+		buildOutput: make(map[string]*strings.Builder),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var event testEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &event) != nil {
+			// Not an event, e.g. output of a test binary that writes to stdout directly
+			p.text.Write(line)
+			p.text.WriteByte('\n')
+			continue
+		}
+		p.event(event)
+	}
+	return p.results(), p.text.String()
+}
+
+// event records one test2json event
+func (p *goTestParser) event(event testEvent) {
+	if event.Action == "build-output" {
+		p.build(event)
+		return
+	}
+	if event.Package == "" {
+		return
+	}
+
+	p.pkg(event.Package)
+	key := event.Package + "\x00" + event.Test
+	if event.Test != "" && p.tests[key] == nil {
+		p.tests[key] = &GoTest{Name: event.Test, Status: StatusPassed}
+		p.testOutput[key] = &strings.Builder{}
+		p.order[event.Package] = append(p.order[event.Package], key)
+	}
+
+	switch event.Action {
+	case "output":
+		p.output(event, key)
+	case "pass", "fail", "skip":
+		p.end(event, key)
+	}
+}
+
+// pkg returns the result for a package, adding it on its first event
+func (p *goTestParser) pkg(name string) *PackageResult {
+	if result, ok := p.byName[name]; ok {
+		return result
+	}
+	result := &PackageResult{Package: name, Status: StatusPassed}
+	p.byName[name] = result
+	p.packages = append(p.packages, result)
+	p.pkgOutput[name] = &strings.Builder{}
+	return result
+}
+
+// build records compiler output, which belongs to an import path rather
+// than a package under test
+func (p *goTestParser) build(event testEvent) {
+	if p.buildOutput[event.ImportPath] == nil {
+		p.buildOutput[event.ImportPath] = &strings.Builder{}
+	}
+	p.buildOutput[event.ImportPath].WriteString(event.Output)
+	p.text.WriteString(event.Output)
+}
+
+// output records a line printed by a test or, outside any test, its package
+func (p *goTestParser) output(event testEvent, key string) {
+	p.text.WriteString(event.Output)
+	if event.Test != "" {
+		p.testOutput[key].WriteString(event.Output)
+	} else {
+		p.pkgOutput[event.Package].WriteString(event.Output)
+	}
+}
+
+// end records the outcome of a test or package; a package that failed to
+// build is given the compiler output of the import path that broke it
+func (p *goTestParser) end(event testEvent, key string) {
+	status := map[string]string{"pass": StatusPassed, "fail": StatusFailed, "skip": StatusSkipped}[event.Action]
+	if event.Test != "" {
+		p.tests[key].Status = status
+		p.tests[key].Duration = event.Elapsed
+		return
+	}
+	result := p.pkg(event.Package)
+	result.Status = status
+	result.Duration = event.Elapsed
+	if event.FailedBuild != "" && p.buildOutput[event.FailedBuild] != nil {
+		p.pkgOutput[event.Package].WriteString(p.buildOutput[event.FailedBuild].String())
+	}
+}
+
+// results returns the packages in the order they started, keeping output
+// and failure details only for what failed
+func (p *goTestParser) results() []PackageResult {
+	results := make([]PackageResult, 0, len(p.packages))
+	for _, result := range p.packages {
+		for _, key := range p.order[result.Package] {
+			test := *p.tests[key]
+			if test.Status == StatusFailed {
+				output := p.testOutput[key].String()
+				test.Output = strings.TrimSpace(output)
+				test.File, test.Line = failureSite(output)
+				test.Panic = panicSection(output)
+				test.Races = raceSections(output)
+			}
+			result.Tests = append(result.Tests, test)
+		}
+
+		output := p.pkgOutput[result.Package].String()
+		if result.Status == StatusFailed {
+			result.Output = strings.TrimSpace(output)
+			result.Panic = panicSection(output)
+			result.Races = raceSections(output)
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// failedTests lists the failed tests of packages as "package.Test (file:line)"
+func failedTests(packages []PackageResult) []string {
+	failed := make([]string, 0)
+	for _, p := range packages {
+		for _, test := range p.Tests {
+			if test.Status != StatusFailed || failedSubtest(p.Tests, test.Name) {
+				continue
+			}
+			name := fmt.Sprintf("%s.%s", p.Package, test.Name)
+			if test.File != "" {
+				name += fmt.Sprintf(" (%s:%d)", test.File, test.Line)
+			}
+			failed = append(failed, name)
+		}
+		if p.Status == StatusFailed && len(p.Tests) == 0 {
+			failed = append(failed, p.Package)
+		}
+	}
+	return failed
+}
+
+// failedSubtest reports whether a subtest of name failed, which also fails name
+func failedSubtest(tests []GoTest, name string) bool {
+	for _, test := range tests {
+		if test.Status == StatusFailed && strings.HasPrefix(test.Name, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// failureSite returns where a test failed: the first file:line reported by
+// t.Error or t.Fatal, or else the first test file frame of a stack trace
+func failureSite(output string) (string, int) {
+	lines := strings.Split(output, "\n")
+	for _, re := range []*regexp.Regexp{failureLocation, stackFrame} {
+		for _, line := range lines {
+			m := re.FindStringSubmatch(line)
+			// testing.go reports races and panics, not the test's own failure
+			if m == nil || filepath.Base(m[1]) == "testing.go" {
+				continue
+			}
+			n, _ := strconv.Atoi(m[2])
+			return m[1], n
+		}
+	}
+	return "", 0
+}
+
+// panicSection returns the panic message and stack trace in output
+func panicSection(output string) string {
+	i := strings.Index(output, "panic: ")
+	if i < 0 {
+		return ""
+	}
+	section := output[i:]
+	if end := strings.Index(section, "\nFAIL\t"); end >= 0 {
+		section = section[:end]
+	}
+	return strings.TrimSpace(section)
+}
+
+// raceSections returns the race detector reports in output
+func raceSections(output string) []string {
+	races := make([]string, 0)
+	for {
+		start := strings.Index(output, "WARNING: DATA RACE")
+		if start < 0 {
+			return races
+		}
+		end := strings.Index(output[start:], raceSeparator)
+		if end < 0 {
+			return append(races, strings.TrimSpace(output[start:]))
+		}
+		races = append(races, strings.TrimSpace(output[start:start+end]))
+		output = output[start+end:]
+	}
+}
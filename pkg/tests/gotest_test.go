@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []string
+		want     []PackageResult
+		wantText string
+	}{
+		{
+			name: "passing package and stray output",
+			events: []string{
+				`{"Action":"start","Package":"ex/a"}`,
+				`{"Action":"run","Package":"ex/a","Test":"TestA"}`,
+				`{"Action":"output","Package":"ex/a","Test":"TestA","Output":"=== RUN   TestA\n"}`,
+				`{"Action":"pass","Package":"ex/a","Test":"TestA","Elapsed":0.5}`,
+				`not json`,
+				`{"Action":"output","Package":"ex/a","Output":"ok  \tex/a\t0.6s\n"}`,
+				`{"Action":"pass","Package":"ex/a","Elapsed":0.6}`,
+			},
+			want: []PackageResult{{Package: "ex/a", Status: StatusPassed, Duration: 0.6, Tests: []GoTest{
+				{Name: "TestA", Status: StatusPassed, Duration: 0.5},
+			}}},
+			wantText: "=== RUN   TestA\nnot json\nok  \tex/a\t0.6s\n",
+		},
+		{
+			name: "failed subtest",
+			events: []string{
+				`{"Action":"run","Package":"ex/b","Test":"TestB"}`,
+				`{"Action":"run","Package":"ex/b","Test":"TestB/case"}`,
+				`{"Action":"output","Package":"ex/b","Test":"TestB/case","Output":"    b_test.go:12: got 1, want 2\n"}`,
+				`{"Action":"fail","Package":"ex/b","Test":"TestB/case","Elapsed":0.1}`,
+				`{"Action":"fail","Package":"ex/b","Test":"TestB","Elapsed":0.2}`,
+				`{"Action":"output","Package":"ex/b","Output":"FAIL\tex/b\t0.3s\n"}`,
+				`{"Action":"fail","Package":"ex/b","Elapsed":0.3}`,
+			},
+			want: []PackageResult{{Package: "ex/b", Status: StatusFailed, Duration: 0.3, Output: "FAIL\tex/b\t0.3s", Races: []string{}, Tests: []GoTest{
+				{Name: "TestB", Status: StatusFailed, Duration: 0.2, Races: []string{}},
+				{Name: "TestB/case", Status: StatusFailed, Duration: 0.1, File: "b_test.go", Line: 12, Output: "b_test.go:12: got 1, want 2", Races: []string{}},
+			}}},
+			wantText: "    b_test.go:12: got 1, want 2\nFAIL\tex/b\t0.3s\n",
+		},
+		{
+			name: "panic",
+			events: []string{
+				`{"Action":"run","Package":"ex/c","Test":"TestC"}`,
+				`{"Action":"output","Package":"ex/c","Test":"TestC","Output":"panic: boom\n"}`,
+				`{"Action":"output","Package":"ex/c","Test":"TestC","Output":"\tex/c/c_test.go:7 +0x1d\n"}`,
+				`{"Action":"fail","Package":"ex/c","Test":"TestC","Elapsed":0}`,
+				`{"Action":"fail","Package":"ex/c","Elapsed":0.1}`,
+			},
+			want: []PackageResult{{Package: "ex/c", Status: StatusFailed, Duration: 0.1, Races: []string{}, Tests: []GoTest{
+				{Name: "TestC", Status: StatusFailed, File: "ex/c/c_test.go", Line: 7, Output: "panic: boom\n\tex/c/c_test.go:7 +0x1d", Panic: "panic: boom\n\tex/c/c_test.go:7 +0x1d", Races: []string{}},
+			}}},
+			wantText: "panic: boom\n\tex/c/c_test.go:7 +0x1d\n",
+		},
+		{
+			name: "build failure",
+			events: []string{
+				`{"ImportPath":"ex/d [ex/d.test]","Action":"build-output","Output":"# ex/d\n"}`,
+				`{"ImportPath":"ex/d [ex/d.test]","Action":"build-output","Output":"d.go:3:1: syntax error\n"}`,
+				`{"Action":"start","Package":"ex/d"}`,
+				`{"Action":"output","Package":"ex/d","Output":"FAIL\tex/d [build failed]\n"}`,
+				`{"Action":"fail","Package":"ex/d","Elapsed":0,"FailedBuild":"ex/d [ex/d.test]"}`,
+			},
+			want:     []PackageResult{{Package: "ex/d", Status: StatusFailed, Output: "FAIL\tex/d [build failed]\n# ex/d\nd.go:3:1: syntax error", Races: []string{}}},
+			wantText: "# ex/d\nd.go:3:1: syntax error\nFAIL\tex/d [build failed]\n",
+		},
+		{
+			name: "package without tests",
+			events: []string{
+				`{"Action":"output","Package":"ex/e","Output":"?   \tex/e\t[no test files]\n"}`,
+				`{"Action":"skip","Package":"ex/e","Elapsed":0}`,
+			},
+			want:     []PackageResult{{Package: "ex/e", Status: StatusSkipped}},
+			wantText: "?   \tex/e\t[no test files]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, text := parseGoTestJSON([]byte(strings.Join(tt.events, "\n")))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if text != tt.wantText {
+				t.Errorf("got text %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestFailedTests(t *testing.T) {
+	packages := []PackageResult{
+		{Package: "ex/b", Status: StatusFailed, Tests: []GoTest{
+			{Name: "TestB", Status: StatusFailed},
+			{Name: "TestB/case", Status: StatusFailed, File: "b_test.go", Line: 12},
+			{Name: "TestOK", Status: StatusPassed},
+		}},
+		{Package: "ex/d", Status: StatusFailed},
+		{Package: "ex/e", Status: StatusSkipped},
+	}
+	want := []string{"ex/b.TestB/case (b_test.go:12)", "ex/d"}
+	if got := failedTests(packages); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+// graph tracks which tests are ready to run as their dependencies finish
+type graph struct {
+	tests      []config.TestConfig
+	dependents [][]int
+	waiting    []int      // unfinished dependencies per test
+	failed     [][]string // failed blocking prerequisites per test
+}
+
+// newGraph builds the dependency graph of tests; dependencies outside tests
+// count as failed when their result in prior is, and are otherwise ignored
+// as config validation reports unknown ones
+func newGraph(tests []config.TestConfig, prior map[string]TestResult) *graph {
+	index := make(map[string]int, len(tests))
+	for i, test := range tests {
+		index[test.Name] = i
+	}
+
+	g := &graph{
+		tests:      tests,
+		dependents: make([][]int, len(tests)),
+		waiting:    make([]int, len(tests)),
+		failed:     make([][]string, len(tests)),
+	}
+	for i, test := range tests {
+		for _, dep := range test.DependsOn {
+			if j, ok := index[dep]; ok {
+				g.dependents[j] = append(g.dependents[j], i)
+				g.waiting[i]++
+			} else if result, ok := prior[dep]; ok && failed(result) {
+				g.failed[i] = append(g.failed[i], dep)
+			}
+		}
+	}
+	return g
+}
+
+// roots returns the tests without dependencies
+func (g *graph) roots() []int {
+	ready := make([]int, 0)
+	for i, n := range g.waiting {
+		if n == 0 {
+			ready = append(ready, i)
+		}
+	}
+	return ready
+}
+
+// finish records the result of test i and returns the dependents that no
+// longer wait on anything
+func (g *graph) finish(i int, result TestResult) []int {
+	ready := make([]int, 0)
+	for _, d := range g.dependents[i] {
+		if failed(result) {
+			g.failed[d] = append(g.failed[d], g.tests[i].Name)
+		}
+		g.waiting[d]--
+		if g.waiting[d] == 0 {
+			ready = append(ready, d)
+		}
+	}
+	return ready
+}
+
+// skipReason explains why test i can't run, or returns "" when it can
+func (g *graph) skipReason(i int) string {
+	if len(g.failed[i]) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("skipped: prerequisite %s did not pass", strings.Join(g.failed[i], ", "))
+}
+
+// failed reports whether a result stops the tests depending on it
+func failed(result TestResult) bool {
+	return result.Status == StatusSkipped || (!result.Success && result.Blocking)
+}
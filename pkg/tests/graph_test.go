@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+func TestGraph(t *testing.T) {
+	// build <- unit, lint; unit <- e2e; e2e also needs "prepare" from an earlier stage
+	g := newGraph([]config.TestConfig{
+		{Name: "build"},
+		{Name: "unit", DependsOn: []string{"build"}},
+		{Name: "lint"},
+		{Name: "e2e", DependsOn: []string{"unit", "prepare"}},
+	}, map[string]TestResult{
+		"prepare": {Name: "prepare", Success: true},
+	})
+
+	if got, want := g.roots(), []int{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got roots %v, want %v", got, want)
+	}
+	if got := g.finish(0, TestResult{Name: "build", Success: true}); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("got ready %v after build, want [1]", got)
+	}
+	if got := g.finish(2, TestResult{Name: "lint", Success: true}); len(got) != 0 {
+		t.Fatalf("got ready %v after lint, want none", got)
+	}
+	if reason := g.skipReason(1); reason != "" {
+		t.Errorf("expected unit to run, got %q", reason)
+	}
+	if got := g.finish(1, TestResult{Name: "unit", Blocking: true}); !reflect.DeepEqual(got, []int{3}) {
+		t.Fatalf("got ready %v after unit, want [3]", got)
+	}
+	if reason, want := g.skipReason(3), "skipped: prerequisite unit did not pass"; reason != want {
+		t.Errorf("got %q, want %q", reason, want)
+	}
+}
+
+func TestGraph_Prerequisites(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TestResult
+		skip   bool
+	}{
+		{"passed", TestResult{Success: true, Blocking: true}, false},
+		{"advisory failure", TestResult{Blocking: false}, false},
+		{"blocking failure", TestResult{Blocking: true}, true},
+		{"skipped", TestResult{Status: StatusSkipped}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prior := TestResult{Name: "prepare", Success: tt.result.Success, Blocking: tt.result.Blocking, Status: tt.result.Status}
+			g := newGraph([]config.TestConfig{{Name: "e2e", DependsOn: []string{"prepare"}}}, map[string]TestResult{"prepare": prior})
+			if skip := g.skipReason(0) != ""; skip != tt.skip {
+				t.Errorf("got skip %v, want %v", skip, tt.skip)
+			}
+		})
+	}
+}
+
+func TestGraph_CycleHasNoRoots(t *testing.T) {
+	g := newGraph([]config.TestConfig{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c"},
+	}, nil)
+
+	if got := g.roots(); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("got roots %v, want only the test outside the cycle", got)
+	}
+	if got := g.finish(2, TestResult{Name: "c", Success: true}); len(got) != 0 {
+		t.Errorf("got ready %v, want none", got)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package tests
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group so the whole
+// tree it spawns can be signalled at once
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateGroup asks every process in the command's group to exit
+func terminateGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// killGroup forcibly stops every process in the command's group
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// groupProcesses lists the live processes of the command's group as "pid args"
+func groupProcesses(cmd *exec.Cmd) []string {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,pgid=,args=").Output()
+	if err != nil {
+		return nil
+	}
+
+	pgid := strconv.Itoa(cmd.Process.Pid)
+	processes := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != pgid || strings.Contains(line, "<defunct>") {
+			continue
+		}
+		processes = append(processes, fields[0]+" "+strings.Join(fields[2:], " "))
+	}
+	return processes
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package tests
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group so the whole
+// tree it spawns can be stopped at once
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// terminateGroup asks the command and its descendants to exit
+func terminateGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// killGroup forcibly stops the command and its descendants
+func killGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// groupProcesses is not supported on Windows, where listing a process tree
+// needs WMI
+func groupProcesses(cmd *exec.Cmd) []string {
+	return nil
+}
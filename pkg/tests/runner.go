@@ -4,27 +4,57 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/danial2026/git_guardian_mcp/pkg/config"
 )
 
+// Test statuses
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // a blocking prerequisite failed
+)
+
 // TestResult represents the result of a test run
 type TestResult struct {
 	Name     string  `json:"name"`
+	Status   string  `json:"status"`
 	Success  bool    `json:"success"`
 	Blocking bool    `json:"blocking"`
 	Duration float64 `json:"duration"` // in seconds
 	Output   string  `json:"output"`
 	Error    string  `json:"error,omitempty"`
+
+	// Packages breaks down go test commands, which run with -json
+	Packages []PackageResult `json:"packages,omitempty"`
+
+	// Set when a timed out or cancelled test was killed
+	Survivors   []string `json:"survivors,omitempty"`    // processes still running after SIGTERM, as "pid args"
+	OutputBytes int      `json:"output_bytes,omitempty"` // output captured before the kill
 }
 
+// killGrace is how long a timed out test's processes get to exit after
+// SIGTERM before they are killed
+const killGrace = 5 * time.Second
+
+// groupPollInterval is how often a stopping test's process group is listed
+const groupPollInterval = 100 * time.Millisecond
+
+// ProgressFunc is called after each test completes; index is 1-based
+type ProgressFunc func(index, total int, result TestResult)
+
 // Runner handles test execution
 type Runner struct {
 	repoPath string
 	config   *config.Config
+	progress ProgressFunc
+	finished map[string]TestResult // results of earlier stages, by test name
 }
 
 // NewRunner creates a new test runner
@@ -32,73 +62,293 @@ func NewRunner(repoPath string, config *config.Config) *Runner {
 	return &Runner{
 		repoPath: repoPath,
 		config:   config,
+		finished: make(map[string]TestResult),
 	}
 }
 
-// RunAll executes all configured tests
-func (r *Runner) RunAll() []TestResult {
-	results := make([]TestResult, 0, len(r.config.Tests))
+// SetProgress registers a callback invoked as each test completes
+func (r *Runner) SetProgress(fn ProgressFunc) {
+	r.progress = fn
+}
 
-	for _, testConfig := range r.config.Tests {
-		result := r.runTest(testConfig)
-		results = append(results, result)
+// RunAll executes all configured tests, starting each when its dependencies
+// finish with up to Parallel running at once, and stops starting tests when
+// ctx is cancelled. Results are in config order
+func (r *Runner) RunAll(ctx context.Context) []TestResult {
+	return r.run(ctx, r.config.Tests)
+}
+
+// RunStage executes the tests of one pipeline stage like RunAll; tests whose
+// blocking prerequisites failed in an earlier stage are skipped
+func (r *Runner) RunStage(ctx context.Context, stage string) []TestResult {
+	return r.run(ctx, r.stageTests(stage))
+}
+
+// Count returns how many tests RunStage runs for stage
+func (r *Runner) Count(stage string) int {
+	return len(r.stageTests(stage))
+}
+
+// stageTests returns the configured tests of a pipeline stage
+func (r *Runner) stageTests(stage string) []config.TestConfig {
+	tests := make([]config.TestConfig, 0)
+	for _, test := range r.config.Tests {
+		if test.Stage == stage || (test.Stage == "" && stage == config.StageTest) {
+			tests = append(tests, test)
+		}
+	}
+	return tests
+}
+
+func (r *Runner) run(ctx context.Context, tests []config.TestConfig) []TestResult {
+	total := len(r.config.Tests)
+	parallel := max(r.config.Parallel, 1)
+
+	type completion struct {
+		index  int
+		result TestResult
 	}
+	done := make(chan completion)
+	finished := make([]*TestResult, len(tests))
+	completed, running := 0, 0
 
+	g := newGraph(tests, r.finished)
+	ready := g.roots()
+
+	// record stores a result and queues the dependents it releases
+	record := func(i int, result TestResult) {
+		finished[i] = &result
+		r.finished[result.Name] = result
+		completed++
+		if r.progress != nil {
+			r.progress(len(r.finished), total, result)
+		}
+		ready = append(ready, g.finish(i, result)...)
+	}
+
+	for completed < len(tests) {
+		for len(ready) > 0 && running < parallel && ctx.Err() == nil {
+			i := ready[0]
+			ready = ready[1:]
+			if reason := g.skipReason(i); reason != "" {
+				record(i, TestResult{Name: tests[i].Name, Status: StatusSkipped, Blocking: tests[i].Blocking, Error: reason})
+				continue
+			}
+			running++
+			go func(i int) {
+				done <- completion{i, r.runTest(ctx, tests[i])}
+			}(i)
+		}
+		if running == 0 {
+			// Cancelled, or the remaining tests wait on a dependency cycle
+			break
+		}
+		c := <-done
+		running--
+		record(c.index, c.result)
+	}
+	return collect(finished)
+}
+
+// collect returns the results of the tests that finished, in config order
+func collect(finished []*TestResult) []TestResult {
+	results := make([]TestResult, 0, len(finished))
+	for _, result := range finished {
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
 	return results
 }
 
-func (r *Runner) runTest(testConfig config.TestConfig) TestResult {
+func (r *Runner) runTest(parent context.Context, testConfig config.TestConfig) TestResult {
 	start := time.Now()
 
 	// Create context with timeout
 	timeout := time.Duration(testConfig.Timeout) * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
-	// Parse command
-	parts := strings.Fields(testConfig.Command)
-	if len(parts) == 0 {
+	cmd, err := r.command(testConfig)
+	if err != nil {
 		return TestResult{
 			Name:     testConfig.Name,
+			Status:   StatusFailed,
 			Success:  false,
 			Blocking: testConfig.Blocking,
 			Duration: 0,
-			Error:    "empty command",
+			Error:    err.Error(),
 		}
 	}
 
-	// Execute command
-	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
-	cmd.Dir = r.repoPath
-
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	// Don't wait forever on pipes held open by processes that left the group
+	cmd.WaitDelay = killGrace
+	survivors, err := execute(ctx, cmd)
 	duration := time.Since(start).Seconds()
 
-	output := stdout.String()
-	if stderr.Len() > 0 {
-		output += "\n" + stderr.String()
-	}
-	output = strings.TrimSpace(output)
-
+	packages, output := testOutput(cmd, &stdout, &stderr)
 	result := TestResult{
 		Name:     testConfig.Name,
+		Status:   StatusPassed,
 		Success:  err == nil,
 		Blocking: testConfig.Blocking,
 		Duration: duration,
-		Output:   output,
+		Output:   strings.TrimSpace(output),
+		Packages: packages,
 	}
-
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			result.Error = fmt.Sprintf("test timed out after %d seconds", testConfig.Timeout)
-		} else {
-			result.Error = err.Error()
+		result.Status = StatusFailed
+		result.Error = failureMessage(parent, ctx, err, testConfig.Timeout, packages)
+	}
+	if err != nil && ctx.Err() != nil {
+		result.Survivors = survivors
+		result.OutputBytes = stdout.Len() + stderr.Len()
+		result.Error += killMessage(survivors, result.OutputBytes)
+	}
+	return result
+}
+
+// testOutput combines a test's output, summarizing go test -json events
+func testOutput(cmd *exec.Cmd, stdout, stderr *bytes.Buffer) ([]PackageResult, string) {
+	output := stdout.String()
+	var packages []PackageResult
+	if isGoTest(cmd.Args) {
+		packages, output = parseGoTestJSON(stdout.Bytes())
+	}
+	if stderr.Len() > 0 {
+		output += "\n" + stderr.String()
+	}
+	return packages, output
+}
+
+// killMessage describes what was left of a test stopped at its timeout
+func killMessage(survivors []string, outputBytes int) string {
+	message := ""
+	if len(survivors) > 0 {
+		message = fmt.Sprintf("; killed %d processes still running %s after SIGTERM: %s",
+			len(survivors), killGrace, strings.Join(survivors, ", "))
+	}
+	return message + fmt.Sprintf("; captured %d bytes of output", outputBytes)
+}
+
+// execute runs cmd until it exits or ctx ends, then stops its process group,
+// returning the processes that had to be killed and the result of waiting
+func execute(ctx context.Context, cmd *exec.Cmd) ([]string, error) {
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return nil, err
+	case <-ctx.Done():
+		return stopProcessGroup(cmd, done)
+	}
+}
+
+// failureMessage explains why a test failed, naming the failed Go tests
+func failureMessage(parent, ctx context.Context, err error, timeout int, packages []PackageResult) string {
+	message := err.Error()
+	if parent.Err() != nil {
+		message = "test cancelled"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		message = fmt.Sprintf("test timed out after %d seconds", timeout)
+	}
+	if failed := failedTests(packages); len(failed) > 0 {
+		message += fmt.Sprintf("; failed: %s", strings.Join(failed, ", "))
+	}
+	return message
+}
+
+// stopProcessGroup sends SIGTERM to the test's process group and waits up to
+// killGrace for all of it to exit; the test's own process may be gone while
+// its children live on, so the group is polled after it exits. Processes
+// still running at the deadline are returned and killed with SIGKILL, along
+// with the result of waiting for the command
+func stopProcessGroup(cmd *exec.Cmd, done <-chan error) ([]string, error) {
+	_ = terminateGroup(cmd)
+
+	var err error
+	exited := false
+	deadline := time.After(killGrace)
+	poll := time.NewTicker(groupPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case err = <-done:
+			exited, done = true, nil
+		case <-poll.C:
+		case <-deadline:
+			survivors := groupProcesses(cmd)
+			_ = killGroup(cmd)
+			if !exited {
+				err = <-done
+			}
+			return survivors, err
+		}
+		if exited && len(groupProcesses(cmd)) == 0 {
+			return nil, err
 		}
 	}
+}
 
-	return result
+// command builds the process for a test from its mode, environment and working directory
+func (r *Runner) command(testConfig config.TestConfig) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	var assignments []string
+	if testConfig.Mode == config.ModeShell {
+		cmd = exec.Command("sh", "-c", testConfig.Command)
+	} else {
+		argv, vars, err := testConfig.Argv()
+		if err != nil {
+			return nil, err
+		}
+		if isGoTest(argv) {
+			argv = withJSON(argv)
+		}
+		cmd = exec.Command(argv[0], argv[1:]...)
+		assignments = vars
+	}
+
+	cmd.Dir = r.repoPath
+	if testConfig.WorkingDir != "" {
+		cmd.Dir = filepath.Join(r.repoPath, testConfig.WorkingDir)
+		if info, err := os.Stat(cmd.Dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("working directory %s does not exist", testConfig.WorkingDir)
+		}
+	}
+
+	cmd.Env = testEnv(testConfig, assignments)
+	setProcessGroup(cmd)
+	return cmd, nil
+}
+
+// testEnv returns the environment of a test: the guardian's own, or only the
+// pass_env variables when clear_env is set, followed by the configured
+// variables and the command's NAME=value prefix, later entries winning
+func testEnv(testConfig config.TestConfig, assignments []string) []string {
+	env := make([]string, 0)
+	if testConfig.ClearEnv {
+		for _, name := range testConfig.PassEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	} else {
+		env = append(env, os.Environ()...)
+	}
+
+	names := make([]string, 0, len(testConfig.Env))
+	for name := range testConfig.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+testConfig.Env[name])
+	}
+	return append(env, assignments...)
 }
@@ -0,0 +1,78 @@
+//go:build unix
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+func TestRunAll_DependencyOrder(t *testing.T) {
+	cfg := &config.Config{
+		Parallel: 2,
+		Tests: []config.TestConfig{
+			{Name: "unit", Command: "true", DependsOn: []string{"build"}, Blocking: true, Timeout: 10},
+			{Name: "build", Command: "false", Blocking: true, Timeout: 10},
+			{Name: "lint", Command: "true", Timeout: 10},
+			{Name: "loop-a", Command: "true", DependsOn: []string{"loop-b"}, Timeout: 10},
+			{Name: "loop-b", Command: "true", DependsOn: []string{"loop-a"}, Timeout: 10},
+		},
+	}
+
+	done := make(chan []TestResult, 1)
+	go func() { done <- NewRunner(t.TempDir(), cfg).RunAll(context.Background()) }()
+	var results []TestResult
+	select {
+	case results = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("RunAll did not return; a dependency cycle must not block it")
+	}
+
+	got := make(map[string]string)
+	for _, result := range results {
+		got[result.Name] = result.Status
+	}
+	want := map[string]string{"build": StatusFailed, "unit": StatusSkipped, "lint": StatusPassed}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("got %s %s, want %s", name, got[name], status)
+		}
+	}
+}
+
+func TestRunTest_TimeoutStopsProcessGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		survivors int
+	}{
+		{"group exits on SIGTERM", `sh -c 'sleep 30 & wait'`, 0},
+		{"child exits after the leader", `sh -c '(trap "sleep 1; exit 0" TERM; while :; do sleep 0.1; done) >/dev/null 2>&1 & wait'`, 0},
+		{"child ignores SIGTERM", `sh -c '(trap "" TERM; exec sleep 30) >/dev/null 2>&1 & wait'`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(t.TempDir(), &config.Config{})
+			start := time.Now()
+			result := r.runTest(context.Background(), config.TestConfig{Name: tt.name, Command: tt.command, Timeout: 1})
+			elapsed := time.Since(start)
+
+			if result.Status != StatusFailed || len(result.Survivors) != tt.survivors {
+				t.Fatalf("got %s with survivors %v, want %s with %d", result.Status, result.Survivors, StatusFailed, tt.survivors)
+			}
+			if tt.survivors == 0 && elapsed >= killGrace {
+				t.Errorf("took %s, want the group to be reaped before the %s grace period", elapsed, killGrace)
+			}
+			if tt.survivors > 0 && elapsed < killGrace {
+				t.Errorf("took %s, want survivors to get the %s grace period", elapsed, killGrace)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/analyzer"
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+	"github.com/danial2026/git_guardian_mcp/pkg/mcp"
+	"github.com/danial2026/git_guardian_mcp/pkg/tests"
+)
+
+// maxPromptDiff caps the diff text embedded in a prompt
+const maxPromptDiff = 40000
+
+var repoArguments = []mcp.PromptArgument{
+	{Name: "repo_path", Description: "Path to the git repository (default: current directory)"},
+	{Name: "remote", Description: "Remote the push targets (default: origin)"},
+	{Name: "branch", Description: "Branch being pushed (default: current branch)"},
+}
+
+// registerPrompts publishes the built-in guided workflows
+func registerPrompts(server *mcp.Server) {
+	server.RegisterPrompt("fix_failed_push", "Walk through fixing the failures of the last validate_push", repoArguments, promptFixFailedPush)
+	server.RegisterPrompt("review_unpushed_commits", "Review the unpushed commits before they are pushed", repoArguments, promptReviewUnpushedCommits)
+	server.RegisterPrompt("write_commit_message", "Write a conventional commit message for the staged changes", []mcp.PromptArgument{
+		{Name: "repo_path", Description: "Path to the git repository (default: current directory)"},
+	}, promptWriteCommitMessage)
+}
+
+func promptFixFailedPush(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+	repoPath := absPath(argOr(args, "repo_path", "."))
+	state, ok := reports.get(repoPath)
+	if !ok || state.LastValidation == nil {
+		return &mcp.PromptResult{
+			Description: "No validation report available",
+			Messages: []mcp.PromptMessage{mcp.UserMessage(fmt.Sprintf(
+				"There is no validate_push report for %s yet. Run the validate_push tool for this repository, then help me fix whatever fails.",
+				repoPath))},
+		}, nil
+	}
+
+	report := state.LastValidation
+	if report.Success {
+		return &mcp.PromptResult{
+			Description: "Last validation passed",
+			Messages: []mcp.PromptMessage{mcp.UserMessage(fmt.Sprintf(
+				"The last validate_push for %s passed, so there is nothing to fix. Summarize what was validated.", repoPath))},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "My push from %s was blocked by git-guardian. Fix the failures below with minimal changes, then tell me how to verify the fix.\n", repoPath)
+
+	failedFiles := writeFailures(&b, report)
+	gitAnalyzer := repoGitAnalyzer(repoPath)
+	commits, err := gitAnalyzer.GetUnpushedCommits(argOr(args, "remote", "origin"), args["branch"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
+	}
+	commits = relevantCommits(repoPath, commits, failedFiles)
+	if err := gitAnalyzer.LoadDiffs(commits); err != nil {
+		return nil, err
+	}
+	writeDiffs(&b, commits)
+
+	return &mcp.PromptResult{
+		Description: "Fix the failures reported by validate_push",
+		Messages:    []mcp.PromptMessage{mcp.UserMessage(b.String())},
+	}, nil
+}
+
+func promptReviewUnpushedCommits(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+	repoPath := absPath(argOr(args, "repo_path", "."))
+	gitAnalyzer := repoGitAnalyzer(repoPath)
+	commits, err := gitAnalyzer.GetUnpushedCommits(argOr(args, "remote", "origin"), args["branch"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
+	}
+	if err := gitAnalyzer.LoadDiffs(commits); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if len(commits) == 0 {
+		fmt.Fprintf(&b, "There are no unpushed commits in %s. Confirm the branch is up to date with its remote.", repoPath)
+	} else {
+		fmt.Fprintf(&b, "Review these %d unpushed commits in %s before I push them. Point out bugs, missing tests, "+
+			"risky changes and commit messages that don't follow the conventional commit format.\n", len(commits), repoPath)
+		writeDiffs(&b, commits)
+	}
+
+	return &mcp.PromptResult{
+		Description: "Review unpushed commits",
+		Messages:    []mcp.PromptMessage{mcp.UserMessage(b.String())},
+	}, nil
+}
+
+func promptWriteCommitMessage(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+	repoPath := absPath(argOr(args, "repo_path", "."))
+	diff, err := git.NewAnalyzer(repoPath).GetStagedDiff()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ask for a message the commit policy checked by validate_push accepts
+	cfg, err := config.Load(repoConfigPath(repoPath))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if strings.TrimSpace(diff) == "" {
+		fmt.Fprintf(&b, "Nothing is staged in %s. Tell me to stage my changes with git add first.", repoPath)
+	} else {
+		b.WriteString(commitMessageRules(commitPolicyConfig(cfg)))
+		b.WriteString("\n\n```diff\n")
+		b.WriteString(truncate(diff, maxPromptDiff))
+		b.WriteString("\n```\n")
+	}
+
+	return &mcp.PromptResult{
+		Description: "Write a commit message for staged changes",
+		Messages:    []mcp.PromptMessage{mcp.UserMessage(b.String())},
+	}, nil
+}
+
+// commitMessageRules describes the commit message the policy accepts
+func commitMessageRules(policy *config.CommitPolicy) string {
+	var b strings.Builder
+	b.WriteString("Write a commit message for the staged changes below")
+	switch {
+	case len(policy.Types) > 0 && policy.RequireScope:
+		fmt.Fprintf(&b, " using the conventional commit format `type(scope): subject` (types: %s)", strings.Join(policy.Types, ", "))
+	case len(policy.Types) > 0:
+		fmt.Fprintf(&b, " using the conventional commit format `type(scope): subject` with an optional scope (types: %s)", strings.Join(policy.Types, ", "))
+	}
+	b.WriteString(".")
+
+	subject := make([]string, 0, 2)
+	if policy.MaxSubjectLength > 0 {
+		subject = append(subject, fmt.Sprintf("at most %d characters", policy.MaxSubjectLength))
+	}
+	if policy.Imperative {
+		subject = append(subject, "in the imperative mood")
+	}
+	if len(subject) > 0 {
+		fmt.Fprintf(&b, " Keep the subject %s.", strings.Join(subject, " and "))
+	}
+	if policy.TicketPattern != "" {
+		fmt.Fprintf(&b, " Reference a ticket matching `%s` in the subject, body or trailers.", policy.TicketPattern)
+	}
+	b.WriteString(" Explain the why in the body.")
+	return b.String()
+}
+
+// writeFailures appends the blocking failures of a report to a prompt and
+// returns the files the failed checks reported
+func writeFailures(b *strings.Builder, report *validatePushResult) map[string]bool {
+	failedFiles := make(map[string]bool)
+	for _, check := range report.Checks {
+		if check.Success || !check.Blocking {
+			continue
+		}
+		fmt.Fprintf(b, "\n## Check failed: %s\n%s\n", check.Tool, analyzer.ExplainFailure(check.Tool, checkDetails(check)))
+		if check.File != "" {
+			failedFiles[check.File] = true
+		}
+		for _, d := range check.Diagnostics {
+			if d.File != "" && !d.Preexisting {
+				failedFiles[d.File] = true
+			}
+		}
+	}
+	for _, test := range report.Tests {
+		if test.Success || !test.Blocking || test.Status == tests.StatusSkipped {
+			continue
+		}
+		fmt.Fprintf(b, "\n## Test failed: %s\n%s\n", test.Name, analyzer.ExplainFailure("test", testDetails(test.Output, test.Error)))
+	}
+	return failedFiles
+}
+
+// relevantCommits keeps the commits touching failed files, or all of them
+// when the failures are not tied to specific files
+func relevantCommits(repoPath string, commits []git.Commit, failedFiles map[string]bool) []git.Commit {
+	if len(failedFiles) == 0 {
+		return commits
+	}
+
+	relevant := make([]git.Commit, 0, len(commits))
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			if failedFiles[filepath.Join(repoPath, file)] || failedFiles[file] {
+				relevant = append(relevant, commit)
+				break
+			}
+		}
+	}
+	if len(relevant) == 0 {
+		return commits
+	}
+	return relevant
+}
+
+// writeDiffs appends commit diffs to a prompt, stopping at maxPromptDiff
+func writeDiffs(b *strings.Builder, commits []git.Commit) {
+	remaining := maxPromptDiff
+	for _, commit := range commits {
+		fmt.Fprintf(b, "\n## Commit %.8s: %s\n", commit.Hash, commit.Message)
+		if commit.Diff == "" {
+			fmt.Fprintf(b, "Files: %s\n", strings.Join(commit.Files, ", "))
+			continue
+		}
+		if remaining <= 0 {
+			b.WriteString("(diff omitted, prompt size limit reached)\n")
+			continue
+		}
+		diff := truncate(commit.Diff, remaining)
+		remaining -= len(diff)
+		fmt.Fprintf(b, "```diff\n%s\n```\n", strings.TrimSpace(diff))
+	}
+}
+
+// checkDetails renders a failed check's findings as file:line:col: message
+// [rule] lines, falling back to its errors or raw output for unstructured checks
+func checkDetails(check analyzer.CheckResult) string {
+	lines := make([]string, 0, len(check.Diagnostics))
+	for _, d := range check.Diagnostics {
+		if !d.Preexisting {
+			lines = append(lines, d.String())
+		}
+	}
+	if len(lines) == 0 {
+		lines = check.Errors
+	}
+	if len(lines) == 0 {
+		return check.Output
+	}
+	return strings.Join(lines, "\n")
+}
+
+func testDetails(output, errMsg string) string {
+	if errMsg == "" {
+		return output
+	}
+	return strings.TrimSpace(errMsg + "\n" + output)
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "\n... (truncated)"
+}
+
+func argOr(args map[string]string, name, fallback string) string {
+	if value := args[name]; value != "" {
+		return value
+	}
+	return fallback
+}
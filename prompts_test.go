@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/analyzer"
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+)
+
+func TestCheckDetails(t *testing.T) {
+	tests := []struct {
+		name  string
+		check analyzer.CheckResult
+		want  string
+	}{
+		{
+			name: "diagnostics",
+			check: analyzer.CheckResult{Diagnostics: []analyzer.Diagnostic{
+				{File: "a.go", Line: 3, Column: 7, Rule: "printf", Message: "wrong verb"},
+				{File: "b.go", Line: 1, Message: "old issue", Preexisting: true},
+				{File: "c.yml", Line: 2, Message: "mapping values are not allowed"},
+			}},
+			want: "a.go:3:7: wrong verb [printf]\nc.yml:2: mapping values are not allowed",
+		},
+		{
+			name:  "errors without diagnostics",
+			check: analyzer.CheckResult{Errors: []string{"first", "second"}, Output: "raw"},
+			want:  "first\nsecond",
+		},
+		{
+			name:  "raw output",
+			check: analyzer.CheckResult{Output: "raw output"},
+			want:  "raw output",
+		},
+		{
+			name: "only pre-existing diagnostics",
+			check: analyzer.CheckResult{
+				Diagnostics: []analyzer.Diagnostic{{File: "b.go", Line: 1, Message: "old", Preexisting: true}},
+				Errors:      []string{"new"},
+			},
+			want: "new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkDetails(tt.check); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitMessageRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *config.CommitPolicy
+		want   string
+	}{
+		{
+			name:   "default policy",
+			policy: config.DefaultCommitPolicy(),
+			want: "Write a commit message for the staged changes below using the conventional commit format `type(scope): subject` " +
+				"with an optional scope (types: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert). " +
+				"Keep the subject at most 72 characters and in the imperative mood. Explain the why in the body.",
+		},
+		{
+			name:   "custom types with a required scope and ticket",
+			policy: &config.CommitPolicy{Types: []string{"feature", "bugfix"}, RequireScope: true, MaxSubjectLength: 50, TicketPattern: "[A-Z]+-[0-9]+"},
+			want: "Write a commit message for the staged changes below using the conventional commit format `type(scope): subject` " +
+				"(types: feature, bugfix). Keep the subject at most 50 characters. Reference a ticket matching `[A-Z]+-[0-9]+` " +
+				"in the subject, body or trailers. Explain the why in the body.",
+		},
+		{
+			name:   "no format",
+			policy: &config.CommitPolicy{},
+			want:   "Write a commit message for the staged changes below. Explain the why in the body.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitMessageRules(tt.policy); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/config"
+	"github.com/danial2026/git_guardian_mcp/pkg/git"
+	"github.com/danial2026/git_guardian_mcp/pkg/mcp"
+)
+
+const (
+	lastValidationURI  = "guardian://repo/{path}/last-validation"
+	configURI          = "guardian://repo/{path}/config"
+	unpushedCommitsURI = "guardian://repo/{path}/unpushed-commits"
+)
+
+// repoState is what the server remembers about a repository it has worked on
+type repoState struct {
+	ConfigPath     string
+	LastValidation *validatePushResult
+	ValidatedAt    time.Time
+}
+
+// reportStore keeps the latest validation report for every repository seen
+type reportStore struct {
+	mu    sync.Mutex
+	repos map[string]*repoState
+
+	server *mcp.Server
+}
+
+var reports = &reportStore{repos: make(map[string]*repoState)}
+
+// registerResources publishes the per-repository resources on the server
+func registerResources(server *mcp.Server) {
+	reports.server = server
+
+	// The working directory is usually the workspace the editor opened
+	if _, err := os.Stat(".git"); err == nil {
+		reports.touch(".", ".mcp.yml")
+	}
+
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: lastValidationURI,
+		Name:        "Last validation",
+		Description: "Most recent validate_push report for the repository",
+		MimeType:    "application/json",
+	}, reports.lister(lastValidationURI, "Last validation", "application/json", true), readLastValidation)
+
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: configURI,
+		Name:        "Guardian config",
+		Description: "The .mcp.yml configuration used for the repository",
+		MimeType:    "application/yaml",
+	}, reports.lister(configURI, "Guardian config", "application/yaml", false), readConfig)
+
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: unpushedCommitsURI,
+		Name:        "Unpushed commits",
+		Description: "Commits on the current branch that are not on the remote yet",
+		MimeType:    "application/json",
+	}, reports.lister(unpushedCommitsURI, "Unpushed commits", "application/json", false), readUnpushedCommits)
+}
+
+// repoURI builds the resource URI of a repository for a template
+func repoURI(template, repoPath string) string {
+	return mcp.ExpandURI(template, map[string]string{"path": repoPath})
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// touch records a repository and reports whether it was new
+func (r *reportStore) touch(repoPath, configPath string) bool {
+	repoPath = absPath(repoPath)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.repos[repoPath]
+	if !ok {
+		state = &repoState{}
+		r.repos[repoPath] = state
+	}
+	if configPath != "" {
+		// Config paths are resolved like config.Load does, against the server's cwd
+		state.ConfigPath = absPath(configPath)
+	}
+	return !ok
+}
+
+// save stores a validation report and notifies subscribed clients
+func (r *reportStore) save(repoPath, configPath string, report *validatePushResult) {
+	isNew := r.touch(repoPath, configPath)
+	repoPath = absPath(repoPath)
+
+	r.mu.Lock()
+	state := r.repos[repoPath]
+	hadReport := state.LastValidation != nil
+	state.LastValidation = report
+	state.ValidatedAt = time.Now()
+	r.mu.Unlock()
+
+	if r.server == nil {
+		return
+	}
+	if isNew || !hadReport {
+		r.server.NotifyResourceListChanged()
+	}
+	r.server.NotifyResourceUpdated(repoURI(lastValidationURI, repoPath))
+}
+
+func (r *reportStore) get(repoPath string) (repoState, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.repos[repoPath]
+	if !ok {
+		return repoState{}, false
+	}
+	return *state, true
+}
+
+// lister returns the resources of a template for every known repository
+func (r *reportStore) lister(template, label, mimeType string, needsReport bool) mcp.ResourceLister {
+	return func(ctx context.Context) []mcp.Resource {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		paths := make([]string, 0, len(r.repos))
+		for path, state := range r.repos {
+			if !needsReport || state.LastValidation != nil {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+
+		resources := make([]mcp.Resource, 0, len(paths))
+		for _, path := range paths {
+			resources = append(resources, mcp.Resource{
+				URI:      repoURI(template, path),
+				Name:     fmt.Sprintf("%s (%s)", label, filepath.Base(path)),
+				MimeType: mimeType,
+			})
+		}
+		return resources
+	}
+}
+
+func readLastValidation(ctx context.Context, uri string, vars map[string]string) (*mcp.ResourceContents, error) {
+	state, ok := reports.get(vars["path"])
+	if !ok || state.LastValidation == nil {
+		return nil, mcp.ErrResourceNotFound
+	}
+
+	return &mcp.ResourceContents{
+		URI:      uri,
+		MimeType: "application/json",
+		Text: mcp.ToJSON(map[string]interface{}{
+			"repo_path":    vars["path"],
+			"validated_at": state.ValidatedAt.Format(time.RFC3339),
+			"report":       state.LastValidation,
+		}),
+	}, nil
+}
+
+// repoConfigPath returns the config last used to validate the repository,
+// or its .mcp.yml
+func repoConfigPath(repoPath string) string {
+	if state, ok := reports.get(repoPath); ok && state.ConfigPath != "" {
+		return state.ConfigPath
+	}
+	return filepath.Join(repoPath, ".mcp.yml")
+}
+
+// repoGitAnalyzer creates a git analyzer honouring the repository's config
+func repoGitAnalyzer(repoPath string) *git.Analyzer {
+	cfg, _ := config.Load(repoConfigPath(repoPath))
+	return newGitAnalyzer(repoPath, cfg)
+}
+
+// knownRepo rejects repositories the server hasn't worked on, so a client
+// can't use the resources to read files or run git anywhere on the host
+func knownRepo(repoPath string) error {
+	if _, ok := reports.get(repoPath); !ok {
+		return &mcp.InvalidParamsError{Message: fmt.Sprintf("repository %s has not been validated by this server", repoPath)}
+	}
+	return nil
+}
+
+func readConfig(ctx context.Context, uri string, vars map[string]string) (*mcp.ResourceContents, error) {
+	if err := knownRepo(vars["path"]); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(repoConfigPath(vars["path"]))
+	if os.IsNotExist(err) {
+		return nil, mcp.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return &mcp.ResourceContents{URI: uri, MimeType: "application/yaml", Text: string(data)}, nil
+}
+
+func readUnpushedCommits(ctx context.Context, uri string, vars map[string]string) (*mcp.ResourceContents, error) {
+	if err := knownRepo(vars["path"]); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(vars["path"]); err != nil {
+		return nil, mcp.ErrResourceNotFound
+	}
+
+	commits, err := repoGitAnalyzer(vars["path"]).GetUnpushedCommits("origin", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpushed commits: %w", err)
+	}
+
+	return &mcp.ResourceContents{
+		URI:      uri,
+		MimeType: "application/json",
+		Text: mcp.ToJSON(map[string]interface{}{
+			"commits":       commits,
+			"total_commits": len(commits),
+		}),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danial2026/git_guardian_mcp/pkg/mcp"
+)
+
+func TestReadConfig_OnlyKnownRepositories(t *testing.T) {
+	known := t.TempDir()
+	unknown := t.TempDir()
+	for _, dir := range []string{known, unknown} {
+		if err := os.WriteFile(filepath.Join(dir, ".mcp.yml"), []byte("tests: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := reports
+	reports = &reportStore{repos: make(map[string]*repoState)}
+	t.Cleanup(func() { reports = saved })
+	reports.touch(known, filepath.Join(known, ".mcp.yml"))
+
+	tests := []struct {
+		name        string
+		repoPath    string
+		wantInvalid bool
+	}{
+		{"validated repository", known, false},
+		{"other directory", unknown, true},
+		{"relative path", "..", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := repoURI(configURI, tt.repoPath)
+			vars := map[string]string{"path": tt.repoPath}
+			for _, read := range []mcp.ResourceReader{readConfig, readUnpushedCommits} {
+				contents, err := read(context.Background(), uri, vars)
+				var invalid *mcp.InvalidParamsError
+				if got := errors.As(err, &invalid); got != tt.wantInvalid {
+					t.Errorf("got error %v, want invalid params %v", err, tt.wantInvalid)
+				}
+				if tt.wantInvalid && contents != nil {
+					t.Errorf("got contents %+v for a rejected repository", contents)
+				}
+			}
+		})
+	}
+}